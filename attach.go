@@ -0,0 +1,192 @@
+package mgmrel
+
+import (
+	"github.com/kamva/mgm/v3"
+	f "github.com/kamva/mgm/v3/field"
+	o "github.com/kamva/mgm/v3/operator"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// DetachMode is the way that Detach removes the related models
+// from the relation.
+type DetachMode string
+
+const (
+	// DetachUnset removes the foreign key field of the detached models.
+	DetachUnset DetachMode = ""
+	// DetachNullify sets the foreign key of the detached models to null.
+	DetachNullify DetachMode = "nullify"
+	// DetachDelete deletes the detached models.
+	DetachDelete DetachMode = "delete"
+)
+
+// OnDetach sets the way that Detach removes the related models
+// from the relation. default is DetachUnset.
+func (r *HasManyRelation) OnDetach(mode DetachMode) *HasManyRelation {
+	r.detachMode = mode
+	return r
+}
+
+// Attach sets the foreign key of the existing models with provided ids
+// to the owner's id by a single query. ordered relations place the
+// attached models after the other related models. it returns the ModelsNotFoundError
+// if some of the models do not exist, and the ForeignOwnershipError if
+// some of them belong to another owner, unless the relation allows reparent.
+func (r *HasManyRelation) Attach(ids ...interface{}) error {
+	ids, err := r.prepareIDs(ids)
+	if err != nil {
+		return err
+	}
+
+	if err := r.requireIDs(bson.M{}, ids); err != nil {
+		return err
+	}
+	if err := r.checkOwnershipByIDs(ids); err != nil {
+		return err
+	}
+	if err := r.checkAdding(ids); err != nil {
+		return err
+	}
+	if err := r.lockOwner(); err != nil {
+		return err
+	}
+
+	filter := bson.M{f.ID: bson.M{o.In: ids}}
+	op := &Op{Operation: OpUpdate, Filter: filter, Update: bson.M{o.Set: bson.M{r.foreignKey: r.m.GetID()}}}
+	if !r.ordered {
+		return r.run(op, func(op *Op) error {
+			_, err := mgm.Coll(r.related).UpdateMany(mgm.Ctx(), op.Filter, op.Update)
+			return err
+		})
+	}
+
+	// Attached models keep their position if they are related
+	// already, and others are placed after the related models.
+	positions, err := r.appendPositions(ids)
+	if err != nil {
+		return err
+	}
+	return r.run(op, func(op *Op) error {
+		return r.attachOrdered(ids, positions)
+	})
+}
+
+// Detach removes the related models with provided ids from the relation
+// by a single query, the way that the relation's detach mode says.
+// it returns the ModelsNotFoundError if some of the models are not
+// related to the owner.
+func (r *HasManyRelation) Detach(ids ...interface{}) error {
+	ids, err := r.prepareIDs(ids)
+	if err != nil {
+		return err
+	}
+	if err := r.requireIDs(r.filterByRelation(nil), ids); err != nil {
+		return err
+	}
+	if err := r.checkRemoving(r.relationFilter(bson.M{f.ID: bson.M{o.In: ids}})); err != nil {
+		return err
+	}
+	if err := r.lockOwner(); err != nil {
+		return err
+	}
+
+	filter := bson.M{f.ID: bson.M{o.In: ids}}
+	switch r.detachMode {
+	case DetachDelete:
+		_, err = r.deleteWhere(filter)
+	case DetachNullify:
+		_, err = r.updateAll(filter, bson.M{o.Set: bson.M{r.foreignKey: nil}})
+	default:
+		_, err = r.updateAll(filter, bson.M{o.Unset: bson.M{r.foreignKey: ""}})
+	}
+	return err
+}
+
+// Move moves the related models with provided ids to the new owner by
+// a single query. ordered relations place the moved models after the
+// new owner's related models. it returns the ModelsNotFoundError if
+// some of the models are not related to the owner.
+func (r *HasManyRelation) Move(ids []interface{}, newOwner mgm.Model) error {
+	ids, err := r.prepareIDs(ids)
+	if err != nil {
+		return err
+	}
+	if err := r.requireIDs(r.filterByRelation(nil), ids); err != nil {
+		return err
+	}
+	if err := r.checkRemoving(r.relationFilter(bson.M{f.ID: bson.M{o.In: ids}})); err != nil {
+		return err
+	}
+	if err := r.lockOwner(); err != nil {
+		return err
+	}
+
+	filter := bson.M{f.ID: bson.M{o.In: ids}}
+	update := bson.M{o.Set: bson.M{r.foreignKey: newOwner.GetID()}}
+	if !r.ordered {
+		_, err = r.updateAll(filter, update)
+		return err
+	}
+
+	// Moved models are placed after the new owner's related models.
+	target := r.relation
+	target.m = newOwner
+	positions, err := target.appendPositions(ids)
+	if err != nil {
+		return err
+	}
+	op := &Op{Operation: OpUpdate, Filter: r.relationFilter(filter), Update: update}
+	return r.run(op, func(op *Op) error {
+		return target.attachOrdered(ids, positions)
+	})
+}
+
+// prepareIDs converts the ids to the related model's id type.
+// e.g hex strings to object ids.
+func (r *relation) prepareIDs(ids []interface{}) ([]interface{}, error) {
+	prepared := make([]interface{}, len(ids))
+	for i, id := range ids {
+		var err error
+		if prepared[i], err = r.related.PrepareID(id); err != nil {
+			return nil, err
+		}
+	}
+	return prepared, nil
+}
+
+// requireIDs checks all of the ids belong to the related models that
+// match the filter, otherwise returns the ModelsNotFoundError.
+func (r *relation) requireIDs(filter bson.M, ids []interface{}) error {
+	query := bson.M{f.ID: bson.M{o.In: ids}}
+	for k, v := range filter {
+		query[k] = v
+	}
+	found, err := r.findIDs(mgm.Ctx(), query)
+	if err != nil {
+		return err
+	}
+
+	exists := make(map[string]bool, len(found))
+	for _, id := range found {
+		key, err := idKey(id)
+		if err != nil {
+			return err
+		}
+		exists[key] = true
+	}
+
+	var missing []interface{}
+	for _, id := range ids {
+		key, err := idKey(id)
+		if err != nil {
+			return err
+		}
+		if !exists[key] {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) != 0 {
+		return &ModelsNotFoundError{IDs: missing}
+	}
+	return nil
+}
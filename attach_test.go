@@ -0,0 +1,99 @@
+package mgmrel_test
+
+import (
+	"errors"
+	mgmrel "github.com/kamva/mgm-relation"
+	"github.com/kamva/mgm/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"testing"
+)
+
+func TestHasManyRelation_Attach(t *testing.T) {
+	setupDefConnection()
+	resetCollection()
+	d := NewDoc("Ali", 12)
+	require.NoError(t, mgm.Coll(d).Create(d))
+	// An author without owner.
+	res, err := mgm.Coll(&DocAuthor{}).InsertOne(mgm.Ctx(), bson.M{"name": "Reza"})
+	require.NoError(t, err)
+	authorID := res.InsertedID.(primitive.ObjectID)
+
+	rel := mgmrel.HasMany(d, &DocAuthor{})
+	require.NoError(t, rel.Attach(authorID.Hex()))
+
+	ids, err := rel.IDs()
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{authorID}, ids)
+}
+
+func TestHasManyRelation_Attach_NotFound(t *testing.T) {
+	setupDefConnection()
+	resetCollection()
+	d := NewDoc("Ali", 12)
+	require.NoError(t, mgm.Coll(d).Create(d))
+
+	missing := primitive.NewObjectID()
+	err := mgmrel.HasMany(d, &DocAuthor{}).Attach(missing)
+	require.True(t, errors.Is(err, mgmrel.ErrModelsNotFound))
+	assert.Equal(t, []interface{}{missing}, err.(*mgmrel.ModelsNotFoundError).IDs)
+}
+
+func TestHasManyRelation_Attach_ForeignOwnership(t *testing.T) {
+	setupDefConnection()
+	resetCollection()
+	_, authors := insertHasManyRelation(t)
+	d := NewDoc("Ali", 12)
+	require.NoError(t, mgm.Coll(d).Create(d))
+
+	err := mgmrel.HasMany(d, &DocAuthor{}).Attach(authors[0].ID)
+	assert.True(t, errors.Is(err, mgmrel.ErrForeignOwnership))
+}
+
+func TestHasManyRelation_Detach(t *testing.T) {
+	setupDefConnection()
+	resetCollection()
+	d, authors := insertHasManyRelation(t)
+
+	require.NoError(t, mgmrel.HasMany(d, &DocAuthor{}).Detach(authors[0].ID))
+	raw := bson.M{}
+	require.NoError(t, mgm.Coll(&DocAuthor{}).FindOne(mgm.Ctx(), bson.M{"_id": authors[0].ID}).Decode(&raw))
+	assert.NotContains(t, raw, "doc_id")
+
+	require.NoError(t, mgmrel.HasMany(d, &DocAuthor{}).OnDetach(mgmrel.DetachNullify).Detach(authors[1].ID))
+	raw = bson.M{}
+	require.NoError(t, mgm.Coll(&DocAuthor{}).FindOne(mgm.Ctx(), bson.M{"_id": authors[1].ID}).Decode(&raw))
+	assert.Contains(t, raw, "doc_id")
+	assert.Nil(t, raw["doc_id"])
+}
+
+func TestHasManyRelation_Detach_Delete(t *testing.T) {
+	setupDefConnection()
+	resetCollection()
+	d, authors := insertHasManyRelation(t)
+
+	require.NoError(t, mgmrel.HasMany(d, &DocAuthor{}).OnDetach(mgmrel.DetachDelete).Detach(authors[0].ID))
+	count, err := mgm.Coll(&DocAuthor{}).CountDocuments(mgm.Ctx(), bson.M{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestHasManyRelation_Move(t *testing.T) {
+	setupDefConnection()
+	resetCollection()
+	d, authors := insertHasManyRelation(t)
+	other := NewDoc("B", 13)
+	require.NoError(t, mgm.Coll(other).Create(other))
+
+	require.NoError(t, mgmrel.HasMany(d, &DocAuthor{}).Move([]interface{}{authors[0].ID}, other))
+
+	ids, err := mgmrel.HasMany(other, &DocAuthor{}).IDs()
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{authors[0].ID}, ids)
+
+	// Models of another owner can not be moved.
+	err = mgmrel.HasMany(d, &DocAuthor{}).Move([]interface{}{authors[0].ID}, other)
+	assert.True(t, errors.Is(err, mgmrel.ErrModelsNotFound))
+}
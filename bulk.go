@@ -0,0 +1,73 @@
+package mgmrel
+
+import (
+	"github.com/kamva/mgm/v3"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// updateAll updates the related models that match the filter by a
+// single query. the filter can not override the relation's foreign key.
+func (r *relation) updateAll(filter bson.M, update interface{}) (*mongo.UpdateResult, error) {
+	var res *mongo.UpdateResult
+	op := &Op{Operation: OpUpdate, Filter: r.relationFilter(filter), Update: update}
+	err := r.run(op, func(op *Op) (err error) {
+		res, err = mgm.Coll(r.related).UpdateMany(mgm.Ctx(), op.Filter, op.Update)
+		return
+	})
+	return res, err
+}
+
+// deleteWhere removes the related models that match the filter.
+// the filter can not override the relation's foreign key.
+func (r *relation) deleteWhere(filter bson.M) (*mongo.DeleteResult, error) {
+	var res *mongo.DeleteResult
+	op := &Op{Operation: OpDelete, Filter: r.relationFilter(filter)}
+	err := r.run(op, func(op *Op) (err error) {
+		res, err = r.deleteByFilter(op.Filter)
+		return
+	})
+	return res, err
+}
+
+// relationFilter returns the relation filter merged with the provided filter.
+func (r *relation) relationFilter(filter bson.M) bson.M {
+	result := r.filterByRelation(nil)
+	for k, v := range filter {
+		if k == r.foreignKey {
+			continue
+		}
+		result[k] = v
+	}
+	return result
+}
+
+// UpdateAll updates all of the related models by a single query.
+// e.g: r.UpdateAll(bson.M{"$set": bson.M{"archived": true}})
+func (r *HasManyRelation) UpdateAll(update interface{}) (*mongo.UpdateResult, error) {
+	return r.updateAll(nil, update)
+}
+
+// DeleteAll removes all of the related models.
+func (r *HasManyRelation) DeleteAll() (*mongo.DeleteResult, error) {
+	return r.DeleteWhere(nil)
+}
+
+// DeleteWhere removes the related models that match the filter.
+func (r *HasManyRelation) DeleteWhere(filter bson.M) (*mongo.DeleteResult, error) {
+	if err := r.checkRemoving(r.relationFilter(filter)); err != nil {
+		return nil, err
+	}
+	return r.deleteWhere(filter)
+}
+
+// Update updates the related model by a single query.
+// e.g: r.Update(bson.M{"$set": bson.M{"archived": true}})
+func (r *HasOneRelation) Update(update interface{}) (*mongo.UpdateResult, error) {
+	return r.updateAll(nil, update)
+}
+
+// Delete removes the related model.
+func (r *HasOneRelation) Delete() (*mongo.DeleteResult, error) {
+	return r.deleteWhere(nil)
+}
@@ -0,0 +1,75 @@
+package mgmrel_test
+
+import (
+	mgmrel "github.com/kamva/mgm-relation"
+	"github.com/kamva/mgm/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"testing"
+)
+
+func TestHasManyRelation_UpdateAll(t *testing.T) {
+	setupDefConnection()
+	resetCollection()
+	d, authors := insertHasManyRelation(t)
+	other, _ := insertHasManyRelation(t)
+
+	res, err := mgmrel.HasMany(d, &DocAuthor{}).UpdateAll(bson.M{"$set": bson.M{"name": "updated"}})
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(authors)), res.MatchedCount)
+	assert.Equal(t, int64(len(authors)), res.ModifiedCount)
+
+	names, err := mgmrel.HasMany(other, &DocAuthor{}).Distinct("name")
+	require.NoError(t, err)
+	assert.NotContains(t, names, "updated")
+}
+
+func TestHasManyRelation_DeleteAll(t *testing.T) {
+	setupDefConnection()
+	resetCollection()
+	d, authors := insertHasManyRelation(t)
+	other, _ := insertHasManyRelation(t)
+
+	res, err := mgmrel.HasMany(d, &DocAuthor{}).DeleteAll()
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(authors)), res.DeletedCount)
+
+	count, err := mgm.Coll(&DocAuthor{}).CountDocuments(mgm.Ctx(), bson.M{"doc_id": other.ID})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+}
+
+func TestHasManyRelation_DeleteWhere(t *testing.T) {
+	setupDefConnection()
+	resetCollection()
+	d, authors := insertHasManyRelation(t)
+
+	// The filter can not override the relation's foreign key.
+	res, err := mgmrel.HasMany(d, &DocAuthor{}).DeleteWhere(bson.M{"name": authors[0].Name, "doc_id": bson.M{"$exists": true}})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), res.DeletedCount)
+
+	ids, err := mgmrel.HasMany(d, &DocAuthor{}).IDs()
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{authors[1].ID}, ids)
+}
+
+func TestHasOneRelation_UpdateAndDelete(t *testing.T) {
+	setupDefConnection()
+	resetCollection()
+	d, _ := insertHasOneRelation(t)
+	rel := mgmrel.HasOne(d, &DocAuthor{})
+
+	updateRes, err := rel.Update(bson.M{"$set": bson.M{"name": "updated"}})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), updateRes.ModifiedCount)
+
+	found := &DocAuthor{}
+	require.NoError(t, rel.Get(found))
+	assert.Equal(t, "updated", found.Name)
+
+	deleteRes, err := rel.Delete()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleteRes.DeletedCount)
+}
@@ -0,0 +1,72 @@
+package mgmrel
+
+import (
+	"github.com/kamva/mgm/v3"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// MinItems sets the minimum number of the related models. operations
+// that leave the relation with fewer models return the CardinalityError
+// before writing anything.
+func (r *HasManyRelation) MinItems(n int64) *HasManyRelation {
+	r.minItems = n
+	return r
+}
+
+// MaxItems sets the maximum number of the related models. operations
+// that leave the relation with more models return the CardinalityError
+// before writing anything. zero means no limit.
+func (r *HasManyRelation) MaxItems(n int64) *HasManyRelation {
+	r.maxItems = n
+	return r
+}
+
+// hasCardinality checks whether the relation limits number of its models.
+func (r *HasManyRelation) hasCardinality() bool {
+	return r.minItems > 0 || r.maxItems > 0
+}
+
+// checkCardinality checks the count is between the relation's limits.
+func (r *HasManyRelation) checkCardinality(count int64) error {
+	if count < r.minItems || (r.maxItems > 0 && count > r.maxItems) {
+		return &CardinalityError{Count: count, Min: r.minItems, Max: r.maxItems}
+	}
+	return nil
+}
+
+// checkAdding checks the relation's limits after adding the models
+// with provided ids. ids that are related already count once.
+func (r *HasManyRelation) checkAdding(ids []interface{}) error {
+	if !r.hasCardinality() {
+		return nil
+	}
+
+	others, err := r.countRelated(r.filterByRelation(ids))
+	if err != nil {
+		return err
+	}
+	return r.checkCardinality(others + int64(len(ids)))
+}
+
+// checkRemoving checks the relation's limits after removing the
+// related models that match the filter.
+func (r *HasManyRelation) checkRemoving(filter bson.M) error {
+	if !r.hasCardinality() {
+		return nil
+	}
+
+	total, err := r.countRelated(r.filterByRelation(nil))
+	if err != nil {
+		return err
+	}
+	removed, err := r.countRelated(filter)
+	if err != nil {
+		return err
+	}
+	return r.checkCardinality(total - removed)
+}
+
+// countRelated counts the related models that match the filter.
+func (r *relation) countRelated(filter interface{}) (int64, error) {
+	return mgm.Coll(r.related).CountDocuments(mgm.Ctx(), filter)
+}
@@ -0,0 +1,67 @@
+package mgmrel_test
+
+import (
+	"errors"
+	mgmrel "github.com/kamva/mgm-relation"
+	"github.com/kamva/mgm/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"testing"
+)
+
+func countAuthors(t *testing.T) int64 {
+	count, err := mgm.Coll(&DocAuthor{}).CountDocuments(mgm.Ctx(), bson.M{})
+	require.NoError(t, err)
+	return count
+}
+
+func TestHasManyRelation_MaxItems(t *testing.T) {
+	setupDefConnection()
+	resetCollection()
+	d, authors := insertHasManyRelation(t)
+	rel := mgmrel.HasMany(d, &DocAuthor{}).MaxItems(2)
+
+	err := rel.SyncWithoutRemove([]*DocAuthor{NewDocAuthor("B3", d.ID)})
+	require.True(t, errors.Is(err, mgmrel.ErrCardinality))
+	assert.Equal(t, int64(3), err.(*mgmrel.CardinalityError).Count)
+
+	err = rel.Create(NewDocAuthor("B3", d.ID))
+	assert.True(t, errors.Is(err, mgmrel.ErrCardinality))
+
+	err = rel.Sync([]*DocAuthor{authors[0], authors[1], NewDocAuthor("B3", d.ID)})
+	assert.True(t, errors.Is(err, mgmrel.ErrCardinality))
+	assert.Equal(t, int64(2), countAuthors(t))
+
+	// Syncing existing models does not add to the count.
+	authors[0].Name = "updated"
+	require.NoError(t, rel.SyncWithoutRemove([]*DocAuthor{authors[0]}))
+}
+
+func TestHasManyRelation_MinItems(t *testing.T) {
+	setupDefConnection()
+	resetCollection()
+	d, authors := insertHasManyRelation(t)
+	rel := mgmrel.HasMany(d, &DocAuthor{}).MinItems(2)
+
+	_, err := rel.DeleteWhere(bson.M{"name": authors[0].Name})
+	assert.True(t, errors.Is(err, mgmrel.ErrCardinality))
+
+	_, err = rel.DeleteAll()
+	assert.True(t, errors.Is(err, mgmrel.ErrCardinality))
+
+	err = rel.Detach(authors[0].ID)
+	assert.True(t, errors.Is(err, mgmrel.ErrCardinality))
+
+	err = rel.Sync([]*DocAuthor{authors[0]})
+	assert.True(t, errors.Is(err, mgmrel.ErrCardinality))
+	assert.Equal(t, int64(2), countAuthors(t))
+
+	require.NoError(t, rel.Sync(authors))
+}
+
+func TestCardinalityError(t *testing.T) {
+	err := &mgmrel.CardinalityError{Count: 3, Min: 1, Max: 2}
+	assert.True(t, errors.Is(err, mgmrel.ErrCardinality))
+	assert.Equal(t, "relation cardinality violated: 3 related models, want between 1 and 2", err.Error())
+}
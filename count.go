@@ -0,0 +1,96 @@
+package mgmrel
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/kamva/mgm/v3"
+	"github.com/kamva/mgm/v3/builder"
+	f "github.com/kamva/mgm/v3/field"
+	o "github.com/kamva/mgm/v3/operator"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type countResult struct {
+	ID    interface{} `bson:"_id"`
+	Count int64       `bson:"count"`
+}
+
+// WithCount sets count of the related models of each owner on the owner's
+// integer field with the target field name, using a single `$group` query.
+// owners can be a model, slice of models or pointer to slice of models.
+// e.g: mgmrel.WithCount(docs, mgmrel.HasMany(&Doc{}, &DocAuthor{}), "AuthorsCount")
+func WithCount(owners interface{}, rel Relation, targetField string) error {
+	models := toModelList(owners)
+	if len(models) == 0 {
+		return nil
+	}
+	r := rel.base()
+
+	results := make([]countResult, 0)
+	err := mgm.Coll(r.related).SimpleAggregate(&results,
+		bson.M{o.Match: relatedFilter(r, bson.M{r.foreignKey: bson.M{o.In: extractIDs(models)}})},
+		builder.Group("$"+r.foreignKey, bson.M{"count": bson.M{o.Sum: 1}}),
+	)
+	if err != nil {
+		return err
+	}
+
+	counts := make(map[string]int64, len(results))
+	for _, res := range results {
+		key, err := idKey(res.ID)
+		if err != nil {
+			return err
+		}
+		counts[key] = res.Count
+	}
+
+	for _, m := range models {
+		key, err := idKey(m.GetID())
+		if err != nil {
+			return err
+		}
+		field, err := relationField(m, targetField)
+		if err != nil {
+			return err
+		}
+		if err := setCount(field, counts[key]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CountStages returns the aggregation stages that set count of the related
+// models of each owner on the field with provided name.
+// e.g: mgm.Coll(&Doc{}).SimpleAggregate(&docs, mgmrel.CountStages(rel, "authors_count")...)
+func CountStages(rel Relation, as string) []interface{} {
+	r := rel.base()
+
+	pipeline := bson.A{
+		bson.M{o.Match: bson.M{o.Expr: bson.M{o.Eq: bson.A{"$" + r.foreignKey, "$$owner_id"}}}},
+	}
+	if scope := r.activeScope(); len(scope) != 0 {
+		pipeline = append(pipeline, bson.M{o.Match: scope})
+	}
+	pipeline = append(pipeline, bson.M{o.Project: bson.M{f.ID: 1}})
+
+	let := bson.M{"owner_id": "$" + f.ID}
+	return []interface{}{
+		builder.S(builder.UncorrelatedLookup(collectionName(r.related), let, pipeline, as)),
+		bson.M{o.AddFields: bson.M{as: bson.M{o.Size: "$" + as}}},
+	}
+}
+
+// setCount sets the count on the integer field.
+func setCount(field reflect.Value, count int64) error {
+	switch field.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		field.SetInt(count)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		field.SetUint(uint64(count))
+	default:
+		return fmt.Errorf("%w: %s field is not an integer", ErrRelationFieldNotFound, field.Type())
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package mgmrel_test
+
+import (
+	mgmrel "github.com/kamva/mgm-relation"
+	"github.com/kamva/mgm/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"testing"
+)
+
+type DocWithCount struct {
+	mgmrel.IDField `bson:",inline"`
+
+	Name         string `bson:"name"`
+	AuthorsCount int    `bson:"authors_count"`
+}
+
+func (d *DocWithCount) CollectionName() string {
+	return "docs"
+}
+
+func TestWithCount(t *testing.T) {
+	setupDefConnection()
+	resetCollection()
+	d, authors := insertHasManyRelation(t)
+	other := NewDoc("B", 13)
+	require.NoError(t, mgm.Coll(other).Create(other))
+
+	docs := []*DocWithCount{{IDField: d.IDField}, {IDField: other.IDField, AuthorsCount: 5}}
+	require.NoError(t, mgmrel.WithCount(docs, mgmrel.HasManyWithOptions(&DocWithCount{}, &DocAuthor{}, "doc_id"), "AuthorsCount"))
+	assert.Equal(t, len(authors), docs[0].AuthorsCount)
+	assert.Equal(t, 0, docs[1].AuthorsCount)
+}
+
+func TestCountStages(t *testing.T) {
+	setupDefConnection()
+	resetCollection()
+	d, authors := insertHasManyRelation(t)
+
+	stages := append([]interface{}{bson.M{"$match": bson.M{"_id": d.ID}}},
+		mgmrel.CountStages(mgmrel.HasManyWithOptions(&DocWithCount{}, &DocAuthor{}, "doc_id"), "authors_count")...)
+
+	docs := make([]DocWithCount, 0)
+	require.NoError(t, mgm.Coll(&DocWithCount{}).SimpleAggregate(&docs, stages...))
+	require.Equal(t, 1, len(docs))
+	assert.Equal(t, len(authors), docs[0].AuthorsCount)
+}
@@ -0,0 +1,204 @@
+package mgmrel
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/kamva/gutil"
+	"github.com/kamva/mgm/v3"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// create sets the foreign key of the new models, calls to their sync
+// hooks and the mgm creating hooks, and inserts them.
+func (r *relation) create(models []mgm.Model) error {
+	op := &Op{Operation: OpCreate, Models: models}
+	return r.run(op, func(op *Op) error {
+		if len(op.Models) == 0 {
+			return nil
+		}
+		if err := r.lockOwner(); err != nil {
+			return err
+		}
+
+		docs := make([]interface{}, len(op.Models))
+		for i, m := range op.Models {
+			if err := r.setForeignKey(m); err != nil {
+				return err
+			}
+			if err := callToBeforeSyncHooks(m); err != nil {
+				return err
+			}
+			if err := callToMgmBeforeCreateHooks(m); err != nil {
+				return err
+			}
+			doc, err := insertDoc(m)
+			if err != nil {
+				return err
+			}
+			docs[i] = doc
+		}
+
+		if err := r.insert(docs); err != nil {
+			return err
+		}
+
+		for _, m := range op.Models {
+			if err := callToMgmAfterCreateHooks(m); err != nil {
+				return err
+			}
+			if err := callToAfterSyncHooks(m); err != nil {
+				return err
+			}
+			if r.trackChanges {
+				if err := r.takeSnapshot(m); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// insert inserts the documents by a single query.
+func (r *relation) insert(docs []interface{}) error {
+	coll := mgm.Coll(r.related)
+	if len(docs) == 1 {
+		_, err := coll.InsertOne(mgm.Ctx(), docs[0])
+		return err
+	}
+
+	_, err := coll.InsertMany(mgm.Ctx(), docs, options.InsertMany().SetOrdered(true))
+	return err
+}
+
+// insertDoc returns the model's document to insert, without
+// the readonly fields that the relation never writes.
+func insertDoc(m mgm.Model) (interface{}, error) {
+	readonly := modelFieldTags(m).readonly
+	if len(readonly) == 0 {
+		return m, nil
+	}
+
+	doc, err := toBsonD(m)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(bson.D, 0, len(doc))
+	for _, e := range doc {
+		if !gutil.Contains(readonly, e.Key) {
+			result = append(result, e)
+		}
+	}
+	return result, nil
+}
+
+// setForeignKey sets the owner's id on the related model's field that
+// its bson name is the relation's foreign key.
+func (r *relation) setForeignKey(m mgm.Model) error {
+	v := reflect.ValueOf(m)
+	for v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+
+	field, ok := fieldByBsonName(v, r.foreignKey)
+	if !ok || !field.CanSet() {
+		return fmt.Errorf("%w: %s has no settable %s field", ErrForeignKeyFieldNotFound, v.Type().Name(), r.foreignKey)
+	}
+
+	id := reflect.ValueOf(r.m.GetID())
+	switch {
+	case id.Type().AssignableTo(field.Type()):
+		field.Set(id)
+	case field.Kind() == reflect.Ptr && id.Type().AssignableTo(field.Type().Elem()):
+		ptr := reflect.New(field.Type().Elem())
+		ptr.Elem().Set(id)
+		field.Set(ptr)
+	default:
+		return fmt.Errorf("%w: %s field of %s can not keep %s", ErrForeignKeyFieldNotFound, r.foreignKey, v.Type().Name(), id.Type())
+	}
+	return nil
+}
+
+// isNew checks whether the model's id is not generated yet.
+func isNew(m mgm.Model) bool {
+	id := m.GetID()
+	return id == nil || reflect.ValueOf(id).IsZero()
+}
+
+// Create sets the foreign key of the model and inserts it as a new
+// related model. it calls to the sync hooks and the mgm creating hooks.
+func (r *HasManyRelation) Create(m mgm.Model) error {
+	return r.CreateMany([]mgm.Model{m})
+}
+
+// CreateMany sets the foreign key of the models and inserts them as new
+// related models by a single query. docs must be slice of models.
+// ordered relations place the new models after the other related models.
+func (r *HasManyRelation) CreateMany(docs interface{}) error {
+	models := r.toModels(docs)
+	if err := r.checkAdding(extractIDs(models)); err != nil {
+		return err
+	}
+	if !r.ordered {
+		return r.create(models)
+	}
+
+	// We place the new models after the other related models.
+	next, err := r.nextPosition()
+	if err != nil {
+		return err
+	}
+	var unpositioned []mgm.Model
+	var positions []int64
+	for i, m := range models {
+		if positioned, ok := m.(Positioned); ok {
+			positioned.SetPosition(next + int64(i))
+			continue
+		}
+		unpositioned = append(unpositioned, m)
+		positions = append(positions, next+int64(i))
+	}
+
+	if err := r.create(models); err != nil {
+		return err
+	}
+	return r.writePositions(mgm.Ctx(), extractIDs(unpositioned), positions)
+}
+
+// Save inserts the model if it's new, otherwise sets its
+// foreign key and syncs it without removing other models.
+func (r *HasManyRelation) Save(m mgm.Model) error {
+	if isNew(m) {
+		return r.Create(m)
+	}
+	if err := r.setForeignKey(m); err != nil {
+		return err
+	}
+	return r.SyncWithoutRemove([]mgm.Model{m})
+}
+
+// Create sets the foreign key of the model and inserts it as the related
+// model. it returns ErrRelatedExists if the relation has a related model.
+// use Sync to replace the current related model.
+func (r *HasOneRelation) Create(m mgm.Model) error {
+	if err := r.checkNotRelated(); err != nil {
+		return err
+	}
+	return r.create([]mgm.Model{m})
+}
+
+// checkNotRelated returns ErrRelatedExists if the relation has a related
+// model, even if the model is out of the relation's scope.
+func (r *HasOneRelation) checkNotRelated() error {
+	count, err := mgm.Coll(r.related).CountDocuments(mgm.Ctx(), r.filterByRelation(nil), options.Count().SetLimit(1))
+	if err != nil {
+		return err
+	}
+	if count != 0 {
+		return ErrRelatedExists
+	}
+	return nil
+}
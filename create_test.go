@@ -0,0 +1,103 @@
+package mgmrel_test
+
+import (
+	"errors"
+	mgmrel "github.com/kamva/mgm-relation"
+	"github.com/kamva/mgm/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"testing"
+)
+
+type creatingAuthor struct {
+	DocAuthor `bson:",inline"`
+
+	creating bool
+	synced   bool
+}
+
+func (a *creatingAuthor) CollectionName() string {
+	return "doc_authors"
+}
+
+func (a *creatingAuthor) Creating() error {
+	a.creating = true
+	return nil
+}
+
+func (a *creatingAuthor) Synced() error {
+	a.synced = true
+	return nil
+}
+
+func TestHasManyRelation_Create(t *testing.T) {
+	setupDefConnection()
+	resetCollection()
+	d := NewDoc("Ali", 12)
+	require.NoError(t, mgm.Coll(d).Create(d))
+
+	author := &creatingAuthor{DocAuthor: DocAuthor{Name: "Reza"}}
+	require.NoError(t, mgmrel.HasMany(d, &DocAuthor{}).Create(author))
+	assert.Equal(t, d.ID, author.DocID)
+	assert.False(t, author.ID.IsZero())
+	assert.True(t, author.creating)
+	assert.True(t, author.synced)
+
+	found := &DocAuthor{}
+	require.NoError(t, mgm.Coll(found).FindByID(author.ID, found))
+	assert.Equal(t, d.ID, found.DocID)
+}
+
+func TestHasManyRelation_CreateMany(t *testing.T) {
+	setupDefConnection()
+	resetCollection()
+	d := NewDoc("Ali", 12)
+	require.NoError(t, mgm.Coll(d).Create(d))
+
+	authors := []*DocAuthor{{Name: "B1"}, {Name: "B2"}}
+	require.NoError(t, mgmrel.HasMany(d, &DocAuthor{}).CreateMany(authors))
+
+	ids, err := mgmrel.HasMany(d, &DocAuthor{}).IDs()
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{authors[1].ID, authors[0].ID}, ids)
+}
+
+func TestHasManyRelation_Save(t *testing.T) {
+	setupDefConnection()
+	resetCollection()
+	d, authors := insertHasManyRelation(t)
+	rel := mgmrel.HasMany(d, &DocAuthor{})
+
+	authors[0].Name = "updated"
+	require.NoError(t, rel.Save(authors[0]))
+	require.NoError(t, rel.Save(&DocAuthor{Name: "new"}))
+
+	names := make([]string, 0)
+	require.NoError(t, rel.DefaultSort("name").Pluck("name", &names))
+	assert.Equal(t, []string{authors[1].Name, "new", "updated"}, names)
+}
+
+func TestHasOneRelation_Create(t *testing.T) {
+	setupDefConnection()
+	resetCollection()
+	d := NewDoc("Ali", 12)
+	require.NoError(t, mgm.Coll(d).Create(d))
+	rel := mgmrel.HasOne(d, &DocAuthor{})
+
+	require.NoError(t, rel.Create(&DocAuthor{Name: "Reza"}))
+	err := rel.Create(&DocAuthor{Name: "Omid"})
+	assert.Equal(t, mgmrel.ErrRelatedExists, err)
+}
+
+type authorWithoutForeignKey struct {
+	mgmrel.IDField `bson:",inline"`
+
+	OwnerID primitive.ObjectID `bson:"owner_id"`
+}
+
+func TestHasManyRelation_Create_ForeignKeyFieldNotFound(t *testing.T) {
+	d := NewDoc("Ali", 12)
+	err := mgmrel.HasMany(d, &authorWithoutForeignKey{}).Create(&authorWithoutForeignKey{})
+	assert.True(t, errors.Is(err, mgmrel.ErrForeignKeyFieldNotFound))
+}
@@ -0,0 +1,134 @@
+package mgmrel
+
+import (
+	"github.com/kamva/mgm/v3"
+	f "github.com/kamva/mgm/v3/field"
+	o "github.com/kamva/mgm/v3/operator"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// delete removes the related models, except models with provided ids.
+func (r *relation) delete(exceptIDs []interface{}) (*mongo.DeleteResult, error) {
+	var res *mongo.DeleteResult
+	op := &Op{Operation: OpDelete, Filter: r.filterByRelation(exceptIDs)}
+	err := r.run(op, func(op *Op) (err error) {
+		res, err = r.deleteByFilter(op.Filter)
+		return
+	})
+	return res, err
+}
+
+// deleteByFilter removes the related models that match the filter.
+// if the related model implements the removal hooks, it loads the
+// models that must be removed and calls to their hooks.
+func (r *relation) deleteByFilter(filter interface{}) (*mongo.DeleteResult, error) {
+	if !hasRemoveHooks(r.related) {
+		return mgm.Coll(r.related).DeleteMany(mgm.Ctx(), filter)
+	}
+
+	models, err := r.loadRemoving(filter)
+	if err != nil {
+		return nil, err
+	}
+	return r.deleteModels(models)
+}
+
+// deleteLoaded removes the related models that loadRemoving loaded,
+// except models with provided ids. it runs through the middlewares
+// like delete, and removes just the loaded models that match the
+// operation's filter.
+func (r *relation) deleteLoaded(exceptIDs []interface{}, models []mgm.Model) (*mongo.DeleteResult, error) {
+	var res *mongo.DeleteResult
+	op := &Op{Operation: OpDelete, Filter: r.filterByRelation(exceptIDs)}
+	err := r.run(op, func(op *Op) error {
+		if len(models) == 0 {
+			res = &mongo.DeleteResult{}
+			return nil
+		}
+
+		// Middlewares can change the filter, so we find the loaded
+		// models that still match it.
+		filter := bson.M{o.And: bson.A{op.Filter, bson.M{f.ID: bson.M{o.In: extractIDs(models)}}}}
+		ids, err := r.findIDs(mgm.Ctx(), filter)
+		if err != nil {
+			return err
+		}
+		matched, err := modelsWithIDs(models, ids)
+		if err != nil {
+			return err
+		}
+
+		res, err = r.deleteModels(matched)
+		return err
+	})
+	return res, err
+}
+
+// loadRemoving loads the related models that match the filter and
+// calls to their Removing hooks, so they can veto the removal.
+func (r *relation) loadRemoving(filter interface{}) ([]mgm.Model, error) {
+	models, err := r.findRelated(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, m := range models {
+		if err := callToBeforeRemoveHooks(m); err != nil {
+			return nil, err
+		}
+	}
+	return models, nil
+}
+
+// deleteModels removes the models that loadRemoving loaded,
+// and calls to their Removed hooks.
+func (r *relation) deleteModels(models []mgm.Model) (*mongo.DeleteResult, error) {
+	if len(models) == 0 {
+		return &mongo.DeleteResult{}, nil
+	}
+
+	res, err := mgm.Coll(r.related).DeleteMany(mgm.Ctx(), bson.M{f.ID: bson.M{o.In: extractIDs(models)}})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, m := range models {
+		if err := callToAfterRemoveHooks(m); err != nil {
+			return res, err
+		}
+	}
+	return res, nil
+}
+
+// modelsWithIDs returns the models that their id is in the ids.
+func modelsWithIDs(models []mgm.Model, ids []interface{}) ([]mgm.Model, error) {
+	keys := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		key, err := idKey(id)
+		if err != nil {
+			return nil, err
+		}
+		keys[key] = true
+	}
+
+	var result []mgm.Model
+	for _, m := range models {
+		key, err := idKey(m.GetID())
+		if err != nil {
+			return nil, err
+		}
+		if keys[key] {
+			result = append(result, m)
+		}
+	}
+	return result, nil
+}
+
+func extractIDs(models []mgm.Model) []interface{} {
+	ids := make([]interface{}, len(models))
+	for i, m := range models {
+		ids[i] = m.GetID()
+	}
+	return ids
+}
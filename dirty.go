@@ -0,0 +1,210 @@
+package mgmrel
+
+import (
+	"bytes"
+
+	"github.com/kamva/gutil"
+	"github.com/kamva/mgm/v3"
+	f "github.com/kamva/mgm/v3/field"
+	o "github.com/kamva/mgm/v3/operator"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// snapshot is the bson fields of a model at the time we loaded it.
+type snapshot map[string]bson.RawValue
+
+// changes contains the fields that changed since the model's snapshot.
+type changes struct {
+	set   bson.D
+	unset bson.M
+}
+
+func (c changes) empty() bool {
+	return len(c.set) == 0 && len(c.unset) == 0
+}
+
+// takeSnapshot keeps the model's current fields as its snapshot.
+func (r *relation) takeSnapshot(m mgm.Model) error {
+	snap, err := newSnapshot(m)
+	if err != nil {
+		return err
+	}
+
+	key, err := idKey(m.GetID())
+	if err != nil {
+		return err
+	}
+	if r.snapshots == nil {
+		r.snapshots = make(map[string]snapshot)
+	}
+	r.snapshots[key] = snap
+	return nil
+}
+
+// takeSnapshots keeps snapshot of the loaded models. results is a
+// model or pointer to the slice of models or their pointers.
+func (r *relation) takeSnapshots(results interface{}) error {
+	// The slice can keep models or pointers to the models.
+	for _, m := range toModelList(results) {
+		if err := r.takeSnapshot(m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// snapshot returns the model's snapshot if the relation tracks changes and
+// has the model's snapshot.
+func (r *relation) snapshot(m mgm.Model) (snapshot, bool) {
+	if !r.trackChanges || r.snapshots == nil {
+		return nil, false
+	}
+
+	key, err := idKey(m.GetID())
+	if err != nil {
+		return nil, false
+	}
+	snap, ok := r.snapshots[key]
+	return snap, ok
+}
+
+// unchanged checks whether the model has a snapshot and it's
+// not changed since we took the snapshot.
+func (r *relation) unchanged(m mgm.Model) (bool, error) {
+	snap, ok := r.snapshot(m)
+	if !ok {
+		return false, nil
+	}
+
+	c, err := snap.changes(m)
+	if err != nil {
+		return false, err
+	}
+	return c.empty(), nil
+}
+
+// write writes the model. if the relation has the model's snapshot, it
+// just writes the changed fields, otherwise upserts the whole model.
+func (r *relation) write(m mgm.Model) (*mongo.UpdateResult, error) {
+	snap, ok := r.snapshot(m)
+	if !ok {
+		res, err := r.upsert(m)
+		if err == nil && r.trackChanges {
+			err = r.takeSnapshot(m)
+		}
+		return res, err
+	}
+
+	res, err := r.updateChanges(m, snap)
+	if err != nil {
+		return nil, err
+	}
+	return res, r.takeSnapshot(m)
+}
+
+// updateChanges updates the model's changed fields. if the model
+// does not exist anymore, it upserts the whole model.
+func (r *relation) updateChanges(m mgm.Model, snap snapshot) (*mongo.UpdateResult, error) {
+	c, err := snap.changes(m)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := bson.M{f.ID: m.GetID()}
+	update := bson.M{}
+	if len(c.set) != 0 {
+		update[o.Set] = c.set
+	}
+	if len(c.unset) != 0 {
+		update[o.Unset] = c.unset
+	}
+
+	versioned, isVersioned := m.(Versioned)
+	if isVersioned {
+		filter[VersionFieldName] = versioned.GetVersion()
+		update[o.Inc] = bson.M{VersionFieldName: 1}
+	}
+	if len(update) == 0 {
+		return &mongo.UpdateResult{MatchedCount: 1}, nil
+	}
+
+	res, err := mgm.Coll(r.related).UpdateOne(mgm.Ctx(), filter, update)
+	if err != nil {
+		return nil, err
+	}
+	if res.MatchedCount == 0 {
+		if isVersioned {
+			return nil, &ConcurrentModificationError{ID: m.GetID(), Version: versioned.GetVersion()}
+		}
+		return r.upsert(m)
+	}
+
+	if isVersioned {
+		versioned.SetVersion(versioned.GetVersion() + 1)
+	}
+	return res, nil
+}
+
+func newSnapshot(m mgm.Model) (snapshot, error) {
+	elements, err := modelElements(m)
+	if err != nil {
+		return nil, err
+	}
+
+	snap := make(snapshot, len(elements))
+	for _, e := range elements {
+		snap[e.Key()] = e.Value()
+	}
+	return snap, nil
+}
+
+// modelElements returns the model's bson elements.
+func modelElements(m mgm.Model) ([]bson.RawElement, error) {
+	raw, err := bson.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	return bson.Raw(raw).Elements()
+}
+
+// changes returns the model's fields that changed since the snapshot.
+// it ignores the id, version, insert-only and readonly fields.
+func (s snapshot) changes(m mgm.Model) (changes, error) {
+	elements, err := modelElements(m)
+	if err != nil {
+		return changes{}, err
+	}
+
+	ignored := append(insertOnlyFields(m), modelFieldTags(m).readonly...)
+	ignored = append(ignored, f.ID, VersionFieldName)
+	c := changes{set: bson.D{}, unset: bson.M{}}
+	current := make(map[string]bool, len(elements))
+	for _, e := range elements {
+		key, val := e.Key(), e.Value()
+		current[key] = true
+		if gutil.Contains(ignored, key) {
+			continue
+		}
+		old, ok := s[key]
+		if !ok || old.Type != val.Type || !bytes.Equal(old.Value, val.Value) {
+			c.set = append(c.set, bson.E{Key: key, Value: val})
+		}
+	}
+
+	for key := range s {
+		if !current[key] && !gutil.Contains(ignored, key) {
+			c.unset[key] = ""
+		}
+	}
+	return c, nil
+}
+
+// idKey returns a comparable key of the id value.
+func idKey(id interface{}) (string, error) {
+	t, data, err := bson.MarshalValue(id)
+	if err != nil {
+		return "", err
+	}
+	return string(t) + string(data), nil
+}
@@ -0,0 +1,80 @@
+package mgmrel_test
+
+import (
+	mgmrel "github.com/kamva/mgm-relation"
+	"github.com/kamva/mgm/v3"
+	f "github.com/kamva/mgm/v3/field"
+	o "github.com/kamva/mgm/v3/operator"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"testing"
+)
+
+func TestTrackChanges_SkipsUnchangedModels(t *testing.T) {
+	setupDefConnection()
+	resetCollection()
+	d, authors := insertHasManyRelation(t)
+
+	rel := mgmrel.HasMany(d, &DocAuthor{}).TrackChanges(true)
+	loaded := make([]*DocAuthor, 0)
+	require.NoError(t, rel.Get(&loaded, "_id", 0, 10))
+	require.Equal(t, 2, len(loaded))
+
+	// Another service changes the first author.
+	_, err := mgm.Coll(&DocAuthor{}).UpdateOne(mgm.Ctx(), bson.M{f.ID: authors[0].ID}, bson.M{o.Set: bson.M{"name": "external"}})
+	require.NoError(t, err)
+
+	loaded[1].Name = "changed"
+	require.NoError(t, rel.SyncWithoutRemove(loaded))
+
+	found := &DocAuthor{}
+	require.NoError(t, mgm.Coll(found).First(bson.M{f.ID: authors[0].ID}, found))
+	assert.Equal(t, "external", found.Name)
+
+	require.NoError(t, mgm.Coll(found).First(bson.M{f.ID: authors[1].ID}, found))
+	assert.Equal(t, "changed", found.Name)
+}
+
+func TestTrackChanges_ValueSlice(t *testing.T) {
+	setupDefConnection()
+	resetCollection()
+	d, authors := insertHasManyRelation(t)
+
+	rel := mgmrel.HasMany(d, &DocAuthor{}).TrackChanges(true)
+	loaded := make([]DocAuthor, 0)
+	require.NoError(t, rel.Get(&loaded, "_id", 0, 10))
+	require.Equal(t, 2, len(loaded))
+
+	// Another service changes the first author.
+	_, err := mgm.Coll(&DocAuthor{}).UpdateOne(mgm.Ctx(), bson.M{f.ID: authors[0].ID}, bson.M{o.Set: bson.M{"name": "external"}})
+	require.NoError(t, err)
+
+	loaded[1].Name = "changed"
+	require.NoError(t, rel.SyncWithoutRemove([]*DocAuthor{&loaded[0], &loaded[1]}))
+
+	found := &DocAuthor{}
+	require.NoError(t, mgm.Coll(found).First(bson.M{f.ID: authors[0].ID}, found))
+	assert.Equal(t, "external", found.Name)
+
+	require.NoError(t, mgm.Coll(found).First(bson.M{f.ID: authors[1].ID}, found))
+	assert.Equal(t, "changed", found.Name)
+}
+
+func TestTrackChanges_HasOne(t *testing.T) {
+	setupDefConnection()
+	resetCollection()
+	d, author := insertHasOneRelation(t)
+
+	rel := mgmrel.HasOne(d, &DocAuthor{}).TrackChanges(true)
+	loaded := &DocAuthor{}
+	require.NoError(t, rel.Get(loaded))
+
+	loaded.Name = "changed"
+	require.NoError(t, rel.Sync(loaded))
+
+	found := &DocAuthor{}
+	require.NoError(t, mgm.Coll(found).First(bson.M{f.ID: author.ID}, found))
+	assert.Equal(t, "changed", found.Name)
+	assert.Equal(t, d.ID, found.DocID)
+}
@@ -0,0 +1,183 @@
+package mgmrel
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/kamva/mgm/v3"
+	"github.com/kamva/mgm/v3/builder"
+	f "github.com/kamva/mgm/v3/field"
+	o "github.com/kamva/mgm/v3/operator"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// lookupFieldPrefix prefixes the `$lookup` output fields to
+// avoid conflict with the owner's fields.
+const lookupFieldPrefix = "__rel_"
+
+// lookupStage returns `$lookup` stage that loads the related models of each
+// owner into the `as` field, using the relation's scope, sort, eager limit
+// and projection.
+func (r *relation) lookupStage(as string) bson.M {
+	pipeline := bson.A{
+		bson.M{o.Match: bson.M{o.Expr: bson.M{o.Eq: bson.A{"$" + r.foreignKey, "$$owner_id"}}}},
+	}
+	if scope := r.activeScope(); len(scope) != 0 {
+		pipeline = append(pipeline, bson.M{o.Match: scope})
+	}
+	if r.sort != "" {
+		pipeline = append(pipeline, bson.M{o.Sort: sortFieldToBsonD(r.sort)})
+	}
+	if r.eagerLimit > 0 {
+		pipeline = append(pipeline, bson.M{o.Limit: r.eagerLimit})
+	}
+	if r.projection != nil {
+		pipeline = append(pipeline, bson.M{o.Project: r.projection})
+	}
+
+	let := bson.M{"owner_id": "$" + f.ID}
+	return builder.S(builder.UncorrelatedLookup(collectionName(r.related), let, pipeline, as))
+}
+
+// FindWith finds the first owner model that matches the filter, and loads
+// the registered relations with provided names into the owner's fields with
+// the same names, in one round-trip using the `$lookup` stage.
+// e.g: mgmrel.FindWith(doc, bson.M{"name": "A"}, "Authors", "Profile")
+// relation fields should have the `bson:"-"` tag to not save them with the owner.
+// if not found, returns the Mongo Go driver not found error.
+func FindWith(owner mgm.Model, filter interface{}, relations ...string) error {
+	cur, err := aggregateWith(owner, filter, 1, relations)
+	if err != nil {
+		return err
+	}
+	defer cur.Close(mgm.Ctx())
+
+	if !cur.Next(mgm.Ctx()) {
+		if err := cur.Err(); err != nil {
+			return err
+		}
+		return mongo.ErrNoDocuments
+	}
+	return decodeWith(cur.Current, owner, relations)
+}
+
+// FindAllWith finds the owner models that match the filter, and loads the
+// registered relations with provided names into the owners fields with the
+// same names. results must be pointer to slice of owner models.
+func FindAllWith(results interface{}, filter interface{}, relations ...string) error {
+	slice := reflect.ValueOf(results)
+	if slice.Kind() != reflect.Ptr || slice.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("results must be pointer to slice, got %T", results)
+	}
+	slice = slice.Elem()
+	owner := newSliceElem(slice.Type().Elem())
+
+	cur, err := aggregateWith(owner.Interface().(mgm.Model), filter, 0, relations)
+	if err != nil {
+		return err
+	}
+	defer cur.Close(mgm.Ctx())
+
+	slice.Set(reflect.MakeSlice(slice.Type(), 0, 0))
+	for cur.Next(mgm.Ctx()) {
+		elem := newSliceElem(slice.Type().Elem())
+		if err := decodeWith(cur.Current, elem.Interface().(mgm.Model), relations); err != nil {
+			return err
+		}
+		if slice.Type().Elem().Kind() != reflect.Ptr {
+			elem = elem.Elem()
+		}
+		slice.Set(reflect.Append(slice, elem))
+	}
+	return cur.Err()
+}
+
+// aggregateWith runs aggregation on the owner's collection to find the
+// owners with the related models.
+func aggregateWith(owner mgm.Model, filter interface{}, limit int64, relations []string) (*mongo.Cursor, error) {
+	if filter == nil {
+		filter = bson.M{}
+	}
+
+	pipeline := bson.A{bson.M{o.Match: filter}}
+	if limit > 0 {
+		pipeline = append(pipeline, bson.M{o.Limit: limit})
+	}
+	for _, name := range relations {
+		rel, err := RegisteredRelation(owner, name)
+		if err != nil {
+			return nil, err
+		}
+		pipeline = append(pipeline, rel.base().lookupStage(lookupFieldPrefix+name))
+	}
+
+	return mgm.Coll(owner).Aggregate(mgm.Ctx(), pipeline)
+}
+
+// decodeWith decodes the aggregation result into the owner
+// and its relation fields.
+func decodeWith(raw bson.Raw, owner mgm.Model, relations []string) error {
+	if err := bson.Unmarshal(raw, owner); err != nil {
+		return err
+	}
+
+	for _, name := range relations {
+		field, err := relationField(owner, name)
+		if err != nil {
+			return err
+		}
+
+		related, err := raw.LookupErr(lookupFieldPrefix + name)
+		if err != nil {
+			return err
+		}
+		values, err := related.Array().Values()
+		if err != nil {
+			return err
+		}
+
+		// Slice fields get all related models, other fields get the first one.
+		if field.Kind() == reflect.Slice {
+			ptr := reflect.New(field.Type())
+			if err := related.Unmarshal(ptr.Interface()); err != nil {
+				return err
+			}
+			field.Set(ptr.Elem())
+			continue
+		}
+
+		if len(values) == 0 {
+			field.Set(reflect.Zero(field.Type()))
+			continue
+		}
+		ptr := reflect.New(field.Type())
+		if err := values[0].Unmarshal(ptr.Interface()); err != nil {
+			return err
+		}
+		field.Set(ptr.Elem())
+	}
+	return nil
+}
+
+// relationField returns the owner's field that keeps the relation's models.
+func relationField(owner mgm.Model, name string) (reflect.Value, error) {
+	v := reflect.ValueOf(owner)
+	for v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+
+	field := v.FieldByName(name)
+	if !field.IsValid() || !field.CanSet() {
+		return reflect.Value{}, fmt.Errorf("%w: %s has no settable %s field", ErrRelationFieldNotFound, v.Type().Name(), name)
+	}
+	return field, nil
+}
+
+// newSliceElem returns pointer to new value of the slice's element type.
+func newSliceElem(t reflect.Type) reflect.Value {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return reflect.New(t)
+}
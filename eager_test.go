@@ -0,0 +1,73 @@
+package mgmrel_test
+
+import (
+	mgmrel "github.com/kamva/mgm-relation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"testing"
+)
+
+type DocWithRelations struct {
+	mgmrel.IDField `bson:",inline"`
+
+	Name string `bson:"name"`
+
+	Authors      []*DocAuthor `bson:"-"`
+	FirstAuthors []*DocAuthor `bson:"-"`
+	Profile      *DocAuthor   `bson:"-"`
+}
+
+func (d *DocWithRelations) CollectionName() string {
+	return "docs"
+}
+
+func init() {
+	mgmrel.Register("Authors", mgmrel.HasManyWithOptions(&DocWithRelations{}, &DocAuthor{}, "doc_id").DefaultSort("name"))
+	mgmrel.Register("FirstAuthors", mgmrel.HasManyWithOptions(&DocWithRelations{}, &DocAuthor{}, "doc_id").DefaultSort("name").EagerLimit(1))
+	mgmrel.Register("Profile", mgmrel.HasOneByOptions(&DocWithRelations{}, &DocAuthor{}, "doc_id").DefaultSort("-name"))
+}
+
+func TestFindWith(t *testing.T) {
+	setupDefConnection()
+	resetCollection()
+	d, authors := insertHasManyRelation(t)
+
+	found := &DocWithRelations{}
+	require.NoError(t, mgmrel.FindWith(found, bson.M{"name": d.Name}, "Authors", "Profile"))
+	assert.Equal(t, d.ID, found.ID)
+	require.Equal(t, len(authors), len(found.Authors))
+	for i, author := range authors {
+		assert.Equal(t, author.ID, found.Authors[i].ID)
+		assert.Equal(t, author.Name, found.Authors[i].Name)
+	}
+	require.NotNil(t, found.Profile)
+	assert.Equal(t, authors[1].ID, found.Profile.ID)
+}
+
+func TestFindWith_NotFound(t *testing.T) {
+	setupDefConnection()
+	resetCollection()
+
+	require.Equal(t, mongo.ErrNoDocuments, mgmrel.FindWith(&DocWithRelations{}, bson.M{}, "Authors"))
+}
+
+func TestFindAllWith_EagerLimit(t *testing.T) {
+	setupDefConnection()
+	resetCollection()
+	d, authors := insertHasManyRelation(t)
+
+	found := make([]*DocWithRelations, 0)
+	require.NoError(t, mgmrel.FindAllWith(&found, bson.M{}, "Authors", "FirstAuthors"))
+	require.Equal(t, 1, len(found))
+	assert.Equal(t, d.ID, found[0].ID)
+	assert.Equal(t, 2, len(found[0].Authors))
+	require.Equal(t, 1, len(found[0].FirstAuthors))
+	assert.Equal(t, authors[0].ID, found[0].FirstAuthors[0].ID)
+	assert.Nil(t, found[0].Profile)
+}
+
+func TestFindWith_UnknownRelation(t *testing.T) {
+	require.Error(t, mgmrel.FindWith(&DocWithRelations{}, bson.M{}, "Unknown"))
+}
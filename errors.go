@@ -0,0 +1,167 @@
+package mgmrel
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrEmptyID returns when a model that its id can not be
+	// generated is synced without id.
+	ErrEmptyID = errors.New("model's id is empty")
+
+	// ErrEmptySequence returns when the sequence name of
+	// an Int64IDField is not set.
+	ErrEmptySequence = errors.New("id sequence name is empty")
+
+	// ErrConcurrentModification returns when a versioned model
+	// is changed by someone else since we loaded it.
+	ErrConcurrentModification = errors.New("concurrent modification")
+
+	// ErrForeignOwnership returns when we sync models that
+	// belong to another owner.
+	ErrForeignOwnership = errors.New("models belong to another owner")
+
+	// ErrRelationNotFound returns when there is no registered
+	// relation with the requested name.
+	ErrRelationNotFound = errors.New("relation not found")
+
+	// ErrRelationFieldNotFound returns when the owner model does
+	// not have a field to load the relation's models into.
+	ErrRelationFieldNotFound = errors.New("relation field not found")
+
+	// ErrEmptyReassignTo returns when the integrity fix mode
+	// is FixReassign but the ReassignTo option is empty.
+	ErrEmptyReassignTo = errors.New("reassign fix mode needs the ReassignTo option")
+
+	// ErrReassignToNotFound returns when the integrity fix mode is
+	// FixReassign but the ReassignTo owner does not exist.
+	ErrReassignToNotFound = errors.New("reassign owner not found")
+
+	// ErrInvalidFixMode returns when the integrity fix mode is unknown.
+	ErrInvalidFixMode = errors.New("invalid integrity fix mode")
+
+	// ErrInvalidOperator returns when the comparison operator
+	// of a relation count filter is unknown.
+	ErrInvalidOperator = errors.New("invalid comparison operator")
+
+	// ErrForeignKeyFieldNotFound returns when the related model does
+	// not have a settable field with the foreign key's bson name.
+	ErrForeignKeyFieldNotFound = errors.New("foreign key field not found")
+
+	// ErrRelatedExists returns when we create the related model of
+	// a "has one" relation that already has a related model.
+	ErrRelatedExists = errors.New("related model already exists")
+
+	// ErrModelsNotFound returns when some of the related models
+	// that an operation needs do not exist.
+	ErrModelsNotFound = errors.New("related models not found")
+
+	// ErrNotOrdered returns when we reorder the related
+	// models of a relation that is not ordered.
+	ErrNotOrdered = errors.New("relation is not ordered")
+
+	// ErrCardinality returns when an operation leaves the relation
+	// with fewer or more related models than its limits.
+	ErrCardinality = errors.New("relation cardinality violated")
+)
+
+// ModelError is the error of syncing a single model.
+type ModelError struct {
+	// Index is index of the model in the synced list.
+	Index int
+	ID    interface{}
+	Err   error
+}
+
+func (e *ModelError) Error() string {
+	return fmt.Sprintf("model at index %d (id: %v): %s", e.Index, e.ID, e.Err)
+}
+
+// Unwrap returns the model's error.
+func (e *ModelError) Unwrap() error {
+	return e.Err
+}
+
+// SyncErrors contains errors of the models that failed on sync,
+// when the relation syncs in the ContinueOnError mode.
+type SyncErrors []*ModelError
+
+func (e SyncErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return fmt.Sprintf("%d model(s) failed on sync: %s", len(e), strings.Join(msgs, "; "))
+}
+
+// ConcurrentModificationError is the error of writing a versioned model
+// that is changed by someone else since we loaded it.
+type ConcurrentModificationError struct {
+	ID interface{}
+	// Version is the model's version that we expected.
+	Version int64
+}
+
+func (e *ConcurrentModificationError) Error() string {
+	return fmt.Sprintf("%s: model with id %v is not in version %d", ErrConcurrentModification, e.ID, e.Version)
+}
+
+// Unwrap returns ErrConcurrentModification.
+func (e *ConcurrentModificationError) Unwrap() error {
+	return ErrConcurrentModification
+}
+
+// ForeignOwnershipError is the error of syncing models that belong
+// to another owner.
+type ForeignOwnershipError struct {
+	// IDs is list of the models that belong to another owner.
+	IDs []interface{}
+}
+
+func (e *ForeignOwnershipError) Error() string {
+	return fmt.Sprintf("%s: %v", ErrForeignOwnership, e.IDs)
+}
+
+// Unwrap returns ErrForeignOwnership.
+func (e *ForeignOwnershipError) Unwrap() error {
+	return ErrForeignOwnership
+}
+
+// ModelsNotFoundError is the error of operating on related
+// models that do not exist.
+type ModelsNotFoundError struct {
+	// IDs is list of the models that do not exist.
+	IDs []interface{}
+}
+
+func (e *ModelsNotFoundError) Error() string {
+	return fmt.Sprintf("%s: %v", ErrModelsNotFound, e.IDs)
+}
+
+// Unwrap returns ErrModelsNotFound.
+func (e *ModelsNotFoundError) Unwrap() error {
+	return ErrModelsNotFound
+}
+
+// CardinalityError is the error of an operation that leaves the
+// relation with fewer or more related models than its limits.
+type CardinalityError struct {
+	// Count is number of the related models after the operation.
+	Count int64
+	// Min and Max are the relation's limits. zero Max means no limit.
+	Min, Max int64
+}
+
+func (e *CardinalityError) Error() string {
+	if e.Max == 0 {
+		return fmt.Sprintf("%s: %d related models, want at least %d", ErrCardinality, e.Count, e.Min)
+	}
+	return fmt.Sprintf("%s: %d related models, want between %d and %d", ErrCardinality, e.Count, e.Min, e.Max)
+}
+
+// Unwrap returns ErrCardinality.
+func (e *CardinalityError) Unwrap() error {
+	return ErrCardinality
+}
@@ -1,6 +1,12 @@
 package mgmrel
 
 import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/oklog/ulid/v2"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -41,3 +47,247 @@ func (f *IDField) Syncing() error {
 }
 
 var _ SyncingHook = &IDField{}
+
+// UUIDIDField struct contain model's UUID id field. the id is stored as
+// the canonical string form of the UUID. it also implements the SyncingHook
+// to generate new UUID before sync the model.
+type UUIDIDField struct {
+	ID string `json:"id" bson:"_id,omitempty"`
+}
+
+// PrepareID method prepare id value to using it as id in filtering,...
+// e.g convert uuid.UUID or non-canonical uuid string to canonical string.
+func (f *UUIDIDField) PrepareID(id interface{}) (interface{}, error) {
+	switch v := id.(type) {
+	case uuid.UUID:
+		return v.String(), nil
+	case string:
+		parsed, err := uuid.Parse(v)
+		if err != nil {
+			return nil, err
+		}
+		return parsed.String(), nil
+	}
+
+	return id, nil
+}
+
+// GetID method return model's id
+func (f *UUIDIDField) GetID() interface{} {
+	return f.ID
+}
+
+// SetID set id value of model's id field.
+func (f *UUIDIDField) SetID(id interface{}) {
+	if u, ok := id.(uuid.UUID); ok {
+		f.ID = u.String()
+		return
+	}
+	f.ID = id.(string)
+}
+
+// Syncing generates new UUID if it's empty.
+func (f *UUIDIDField) Syncing() error {
+	if f.ID == "" {
+		f.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// ULIDIDField struct contain model's ULID id field. the id is stored as
+// the string form of the ULID, so ids are sortable by their creation time.
+// it also implements the SyncingHook to generate new ULID before sync the model.
+type ULIDIDField struct {
+	ID string `json:"id" bson:"_id,omitempty"`
+}
+
+// PrepareID method prepare id value to using it as id in filtering,...
+// e.g convert ulid.ULID to string.
+func (f *ULIDIDField) PrepareID(id interface{}) (interface{}, error) {
+	switch v := id.(type) {
+	case ulid.ULID:
+		return v.String(), nil
+	case string:
+		parsed, err := ulid.Parse(v)
+		if err != nil {
+			return nil, err
+		}
+		return parsed.String(), nil
+	}
+
+	return id, nil
+}
+
+// GetID method return model's id
+func (f *ULIDIDField) GetID() interface{} {
+	return f.ID
+}
+
+// SetID set id value of model's id field.
+func (f *ULIDIDField) SetID(id interface{}) {
+	if u, ok := id.(ulid.ULID); ok {
+		f.ID = u.String()
+		return
+	}
+	f.ID = id.(string)
+}
+
+// Syncing generates new ULID if it's empty.
+func (f *ULIDIDField) Syncing() error {
+	if f.ID == "" {
+		f.ID = ulid.Make().String()
+	}
+	return nil
+}
+
+// StringIDField struct contain model's string id field (e.g slugs).
+// string ids can not be generated, so its Syncing hook returns
+// ErrEmptyID if the id is empty.
+type StringIDField struct {
+	ID string `json:"id" bson:"_id,omitempty"`
+}
+
+// PrepareID method prepare id value to using it as id in filtering,...
+func (f *StringIDField) PrepareID(id interface{}) (interface{}, error) {
+	if s, ok := id.(fmt.Stringer); ok {
+		return s.String(), nil
+	}
+	return id, nil
+}
+
+// GetID method return model's id
+func (f *StringIDField) GetID() interface{} {
+	return f.ID
+}
+
+// SetID set id value of model's id field.
+func (f *StringIDField) SetID(id interface{}) {
+	f.ID = id.(string)
+}
+
+// Syncing checks the id is not empty.
+func (f *StringIDField) Syncing() error {
+	if f.ID == "" {
+		return ErrEmptyID
+	}
+	return nil
+}
+
+// Int64IDField struct contain model's int64 id field. it also implements
+// the SyncingHook to set the next value of the model's sequence as id before
+// sync the model. relations use the model's collection name as the sequence
+// name, you can change it by the SetSequence method.
+type Int64IDField struct {
+	ID int64 `json:"id" bson:"_id,omitempty"`
+
+	sequence string
+}
+
+// PrepareID method prepare id value to using it as id in filtering,...
+// e.g convert string or int values to int64.
+func (f *Int64IDField) PrepareID(id interface{}) (interface{}, error) {
+	switch v := id.(type) {
+	case string:
+		return strconv.ParseInt(v, 10, 64)
+	case int:
+		return int64(v), nil
+	case int32:
+		return int64(v), nil
+	}
+
+	return id, nil
+}
+
+// GetID method return model's id
+func (f *Int64IDField) GetID() interface{} {
+	return f.ID
+}
+
+// SetID set id value of model's id field.
+func (f *Int64IDField) SetID(id interface{}) {
+	switch v := id.(type) {
+	case int:
+		f.ID = int64(v)
+	case int32:
+		f.ID = int64(v)
+	default:
+		f.ID = id.(int64)
+	}
+}
+
+// SetSequence sets name of the sequence that generates the model's ids.
+func (f *Int64IDField) SetSequence(name string) {
+	f.sequence = name
+}
+
+// bindSequence sets the sequence name if it's not set yet.
+func (f *Int64IDField) bindSequence(name string) {
+	if f.sequence == "" {
+		f.sequence = name
+	}
+}
+
+// Syncing set the ID from the sequence if it's zero.
+func (f *Int64IDField) Syncing() error {
+	if f.ID != 0 {
+		return nil
+	}
+	if f.sequence == "" {
+		return ErrEmptySequence
+	}
+
+	id, err := NextSequence(f.sequence)
+	if err != nil {
+		return err
+	}
+	f.ID = id
+	return nil
+}
+
+var _ SyncingHook = &UUIDIDField{}
+var _ SyncingHook = &ULIDIDField{}
+var _ SyncingHook = &StringIDField{}
+var _ SyncingHook = &Int64IDField{}
+
+// DateFields struct contain `created_at` and `updated_at` fields that
+// autofill on syncing the model. `created_at` is written just on
+// inserting the model, so syncing an existing model never overwrites it.
+type DateFields struct {
+	CreatedAt time.Time `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" bson:"updated_at"`
+}
+
+// Syncing set the `updated_at` field, and the `created_at` field
+// if it's zero.
+func (f *DateFields) Syncing() error {
+	now := time.Now().UTC()
+	if f.CreatedAt.IsZero() {
+		f.CreatedAt = now
+	}
+	f.UpdatedAt = now
+	return nil
+}
+
+// InsertOnlyFields returns `created_at` to write it just on insert.
+func (f *DateFields) InsertOnlyFields() []string {
+	return []string{"created_at"}
+}
+
+// DefaultModel struct contain model's default fields. you can
+// use it instead of the mgm DefaultModel.
+type DefaultModel struct {
+	IDField    `bson:",inline"`
+	DateFields `bson:",inline"`
+}
+
+// Syncing function call to it's inner fields defined hooks
+func (model *DefaultModel) Syncing() error {
+	if err := model.IDField.Syncing(); err != nil {
+		return err
+	}
+	return model.DateFields.Syncing()
+}
+
+var _ SyncingHook = &DateFields{}
+var _ InsertOnlyFields = &DateFields{}
+var _ SyncingHook = &DefaultModel{}
@@ -1,11 +1,24 @@
 package mgmrel_test
 
 import (
+	"github.com/google/uuid"
+	mgmrel "github.com/kamva/mgm-relation"
+	"github.com/kamva/mgm/v3"
+	"github.com/oklog/ulid/v2"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"testing"
 )
 
+type SeqAuthor struct {
+	mgmrel.Int64IDField `bson:",inline"`
+
+	Name  string             `bson:"name"`
+	DocID primitive.ObjectID `bson:"doc_id"`
+}
+
 func TestIDField_PrepareID(t *testing.T) {
 	id := primitive.NewObjectID()
 	d := &Doc{}
@@ -18,3 +31,107 @@ func TestIDField_PrepareID(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, id, preparedId)
 }
+
+func TestUUIDIDField_PrepareID(t *testing.T) {
+	id := uuid.New()
+	f := &mgmrel.UUIDIDField{}
+
+	preparedId, err := f.PrepareID(id)
+	assert.NoError(t, err)
+	assert.Equal(t, id.String(), preparedId)
+
+	preparedId, err = f.PrepareID("urn:uuid:" + id.String())
+	assert.NoError(t, err)
+	assert.Equal(t, id.String(), preparedId)
+
+	_, err = f.PrepareID("invalid")
+	assert.Error(t, err)
+}
+
+func TestUUIDIDField_Syncing(t *testing.T) {
+	f := &mgmrel.UUIDIDField{}
+	require.NoError(t, f.Syncing())
+	_, err := uuid.Parse(f.ID)
+	assert.NoError(t, err)
+
+	id := f.ID
+	require.NoError(t, f.Syncing())
+	assert.Equal(t, id, f.ID)
+}
+
+func TestULIDIDField_PrepareID(t *testing.T) {
+	id := ulid.Make()
+	f := &mgmrel.ULIDIDField{}
+
+	preparedId, err := f.PrepareID(id)
+	assert.NoError(t, err)
+	assert.Equal(t, id.String(), preparedId)
+
+	preparedId, err = f.PrepareID(id.String())
+	assert.NoError(t, err)
+	assert.Equal(t, id.String(), preparedId)
+
+	_, err = f.PrepareID("invalid")
+	assert.Error(t, err)
+}
+
+func TestULIDIDField_Syncing(t *testing.T) {
+	f := &mgmrel.ULIDIDField{}
+	require.NoError(t, f.Syncing())
+	_, err := ulid.Parse(f.ID)
+	assert.NoError(t, err)
+}
+
+func TestStringIDField_Syncing(t *testing.T) {
+	f := &mgmrel.StringIDField{}
+	assert.Equal(t, mgmrel.ErrEmptyID, f.Syncing())
+
+	f.SetID("my-slug")
+	assert.NoError(t, f.Syncing())
+	assert.Equal(t, "my-slug", f.GetID())
+}
+
+func TestInt64IDField_PrepareID(t *testing.T) {
+	f := &mgmrel.Int64IDField{}
+
+	preparedId, err := f.PrepareID("12")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(12), preparedId)
+
+	preparedId, err = f.PrepareID(12)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(12), preparedId)
+
+	_, err = f.PrepareID("abc")
+	assert.Error(t, err)
+}
+
+func TestInt64IDField_SyncingWithoutSequence(t *testing.T) {
+	f := &mgmrel.Int64IDField{}
+	assert.Equal(t, mgmrel.ErrEmptySequence, f.Syncing())
+}
+
+func TestInt64IDField_SyncWithRelation(t *testing.T) {
+	setupDefConnection()
+	resetCollection()
+	_, err := mgm.Coll(&SeqAuthor{}).DeleteMany(mgm.Ctx(), bson.M{})
+	require.NoError(t, err)
+	_, err = mgm.CollectionByName(mgmrel.SequenceCollection).DeleteMany(mgm.Ctx(), bson.M{})
+	require.NoError(t, err)
+
+	d := NewDoc("A", 12)
+	require.NoError(t, mgm.Coll(d).Create(d))
+
+	authors := []*SeqAuthor{
+		{Name: "B1", DocID: d.ID},
+		{Name: "B2", DocID: d.ID},
+	}
+	require.NoError(t, mgmrel.HasMany(d, &SeqAuthor{}).Sync(authors))
+	assert.Equal(t, int64(1), authors[0].ID)
+	assert.Equal(t, int64(2), authors[1].ID)
+
+	foundAuthors := make([]*SeqAuthor, 0)
+	require.NoError(t, mgmrel.HasMany(d, &SeqAuthor{}).Get(&foundAuthors, "_id", 0, 10))
+	require.Equal(t, 2, len(foundAuthors))
+	assert.Equal(t, authors[0].Name, foundAuthors[0].Name)
+}
@@ -4,8 +4,11 @@ go 1.13
 
 require (
 	github.com/golang/snappy v0.0.2 // indirect
+	github.com/google/uuid v1.3.0
+	github.com/jinzhu/inflection v1.0.0
 	github.com/kamva/gutil v0.0.0-20200802192905-f876666b3671
 	github.com/kamva/mgm/v3 v3.1.0
+	github.com/oklog/ulid/v2 v2.1.0
 	github.com/stretchr/testify v1.5.1
 	go.mongodb.org/mongo-driver v1.3.4
 	golang.org/x/sync v0.0.0-20201020160332-67f06af15bc9 // indirect
@@ -1,70 +1,257 @@
 package mgmrel
 
 import (
+	"fmt"
+	"reflect"
+	"strings"
+
 	"github.com/kamva/gutil"
 	"github.com/kamva/mgm/v3"
 	f "github.com/kamva/mgm/v3/field"
-	o "github.com/kamva/mgm/v3/operator"
 	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+// defaultHasManySort is the default sort field of the "has many" relations.
+const defaultHasManySort = "-_id"
+
 type HasManyRelation struct {
-	m       mgm.Model
-	related mgm.Model
-	// foreignKey uses in filters.
-	foreignKey string
+	relation
+
+	// continueOnError syncs all models even if some of them fail.
+	continueOnError bool
+	// detachMode is the way that Detach removes the related models.
+	detachMode DetachMode
+	// ordered writes position of the models in the synced list.
+	ordered bool
+	// minItems and maxItems limit number of the related models.
+	minItems, maxItems int64
+}
+
+// Scope sets the default filter scope of the relation. it applies
+// on all get methods. e.g `bson.M{"status": "active"}`
+func (r *HasManyRelation) Scope(scope bson.M) *HasManyRelation {
+	r.scope = scope
+	return r
+}
+
+// WithoutScopes returns copy of the relation that does not apply
+// the relation's scope on get methods.
+func (r *HasManyRelation) WithoutScopes() *HasManyRelation {
+	cp := *r
+	cp.withoutScopes = true
+	return &cp
+}
+
+// DefaultSort sets the default sort field of the relation.
+// you can sort descending by adding a `-` to the sort field. e.g `-created_at`
+func (r *HasManyRelation) DefaultSort(sort string) *HasManyRelation {
+	r.sort = sort
+	return r
+}
+
+// Projection sets the default projection of the relation.
+func (r *HasManyRelation) Projection(projection interface{}) *HasManyRelation {
+	r.projection = projection
+	return r
+}
+
+// NamingStrategy sets the relation's foreign key using the provided
+// naming strategy instead of the global naming strategy.
+func (r *HasManyRelation) NamingStrategy(s NamingStrategy) *HasManyRelation {
+	r.foreignKey = s.ForeignKey(r.m)
+	return r
+}
+
+// MgmHooks sets whether the relation dispatches the mgm model hooks
+// (CreatingHook, UpdatingHook, SavingHook, CreatedHook,...) on sync.
+// insert and update are distinguished by checking the model's existence.
+func (r *HasManyRelation) MgmHooks(enable bool) *HasManyRelation {
+	r.mgmHooks = enable
+	return r
+}
+
+// Use adds middlewares that wrap the relation's operations.
+func (r *HasManyRelation) Use(mw ...Middleware) *HasManyRelation {
+	r.middlewares = append(r.middlewares, mw...)
+	return r
+}
+
+// Info returns the relation's metadata.
+func (r *HasManyRelation) Info() RelationInfo {
+	return r.info()
+}
+
+// ContinueOnError sets whether the sync methods continue syncing
+// other models when a model fails. in this mode they return the
+// SyncErrors that contains errors of the failed models.
+func (r *HasManyRelation) ContinueOnError(enable bool) *HasManyRelation {
+	r.continueOnError = enable
+	return r
+}
+
+// TrackChanges sets whether the relation tracks changes of the models
+// that it loads by the get methods. in this mode, sync methods just write
+// the changed fields of the loaded models (using `$set` and `$unset`) and
+// skip the models that are not changed.
+func (r *HasManyRelation) TrackChanges(enable bool) *HasManyRelation {
+	r.trackChanges = enable
+	return r
+}
+
+// AllowReparent lets sync methods move models that belong to another
+// owner to this relation's owner. by default sync methods return the
+// ForeignOwnershipError for those models.
+func (r *HasManyRelation) AllowReparent() *HasManyRelation {
+	r.allowReparent = true
+	return r
+}
+
+// EagerLimit limits number of the related models that eager loading
+// (e.g FindWith) loads per owner. zero means no limit.
+func (r *HasManyRelation) EagerLimit(limit int64) *HasManyRelation {
+	r.eagerLimit = limit
+	return r
 }
 
 // Get method get the list of related models with provided filter,limit,...
+// provided options override the relation's default sort and projection.
 // if not found, returns the Mongo Go driver not found error.
-func (r *HasManyRelation) GetWithOptions(results interface{}, options ...*options.FindOptions) error {
-	return mgm.Coll(r.related).SimpleFind(results, r.filterByRelation(nil), options...)
+func (r *HasManyRelation) GetWithOptions(results interface{}, opts ...*options.FindOptions) error {
+	opts = append([]*options.FindOptions{r.findOptions()}, opts...)
+	op := &Op{Operation: OpGet, Filter: r.scopedFilter(), Results: results}
+	return r.run(op, func(op *Op) error {
+		if err := mgm.Coll(r.related).SimpleFind(op.Results, op.Filter, opts...); err != nil {
+			return err
+		}
+		if r.trackChanges {
+			return r.takeSnapshots(op.Results)
+		}
+		return nil
+	})
 }
 
 // Get method get the list of related models with provided filter,limit,...
+// if sort is empty, it uses the relation's default sort.
 // if not found, returns the Mongo Go driver not found error.
 func (r *HasManyRelation) Get(results interface{}, sort string, skip, limit int64) error {
-	return r.GetWithOptions(results, &options.FindOptions{
+	opts := &options.FindOptions{
 		Limit: &limit,
 		Skip:  &skip,
-		Sort:  r.sortFieldToBsonD(sort),
-	})
+	}
+	if sort != "" {
+		opts.Sort = sortFieldToBsonD(sort)
+	}
+	return r.GetWithOptions(results, opts)
 }
 
-// SimpleGet method get the list of related models
+// SimpleGet method get the list of related models sorted by
+// the relation's default sort.
 // if not found, returns the Mongo Go driver not found error.
-// sort is the sort field. you can sort descending by adding a `-` to the sort field. e.g `-created_at`
 func (r *HasManyRelation) SimpleGet(results interface{}, limit int64) error {
-	return r.Get(results, "-_id", 0, limit)
+	return r.Get(results, "", 0, limit)
 }
 
-// SyncWithoutRemove method sync the relations without
-// removing items that are not in the provided list.
-func (r *HasManyRelation) SyncWithoutRemove(docs interface{}) error {
-	if gutil.IsNil(docs) {
-		return nil
+// Pluck decodes the field of the related models into the results,
+// sorted by the relation's default sort. results must be pointer to
+// slice of the field's type. related models without the field are skipped.
+// e.g: r.Pluck("name", &names)
+func (r *HasManyRelation) Pluck(field string, results interface{}) error {
+	slice := reflect.ValueOf(results)
+	if slice.Kind() != reflect.Ptr || slice.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("results must be pointer to slice, got %T", results)
 	}
-	models := r.toModel(gutil.InterfaceToSlice(docs))
-	if len(models) == 0 {
-		return nil
+	slice = slice.Elem()
+
+	opts := options.Find().SetProjection(bson.M{field: 1})
+	if r.sort != "" {
+		opts.SetSort(sortFieldToBsonD(r.sort))
 	}
-	for _, m := range models {
-		if err := callToBeforeSyncHooks(m); err != nil {
-			return err
-		}
-		_, err := mgm.Coll(r.related).UpdateOne(mgm.Ctx(), bson.M{f.ID: m.GetID()}, bson.M{o.Set: m}, &options.UpdateOptions{
-			Upsert: gutil.NewBool(true),
-		})
+
+	op := &Op{Operation: OpGet, Filter: r.scopedFilter(), Results: results}
+	return r.run(op, func(op *Op) error {
+		cur, err := mgm.Coll(r.related).Find(mgm.Ctx(), op.Filter, opts)
 		if err != nil {
 			return err
 		}
-		if err := callToAfterSyncHooks(m); err != nil {
+		defer cur.Close(mgm.Ctx())
+
+		values := reflect.MakeSlice(slice.Type(), 0, 0)
+		for cur.Next(mgm.Ctx()) {
+			val, err := cur.Current.LookupErr(strings.Split(field, ".")...)
+			if err != nil {
+				continue
+			}
+			elem := reflect.New(slice.Type().Elem())
+			if err := val.Unmarshal(elem.Interface()); err != nil {
+				return err
+			}
+			values = reflect.Append(values, elem.Elem())
+		}
+		if err := cur.Err(); err != nil {
 			return err
 		}
+
+		slice.Set(values)
+		return nil
+	})
+}
+
+// Distinct returns the distinct values of the field
+// in the related models.
+func (r *HasManyRelation) Distinct(field string) ([]interface{}, error) {
+	var values []interface{}
+	op := &Op{Operation: OpGet, Filter: r.scopedFilter(), Results: &values}
+	err := r.run(op, func(op *Op) (err error) {
+		values, err = mgm.Coll(r.related).Distinct(mgm.Ctx(), field, op.Filter)
+		return
+	})
+	return values, err
+}
+
+// IDs returns ids of the related models, sorted by
+// the relation's default sort.
+func (r *HasManyRelation) IDs() ([]interface{}, error) {
+	ids := make([]interface{}, 0)
+	if err := r.Pluck(f.ID, &ids); err != nil {
+		return nil, err
 	}
-	return nil
+	return ids, nil
+}
+
+// SyncWithoutRemove method sync the relations without
+// removing items that are not in the provided list.
+func (r *HasManyRelation) SyncWithoutRemove(docs interface{}) error {
+	op := &Op{Operation: OpSyncWithoutRemove, Models: r.toModels(docs)}
+	return r.run(op, func(op *Op) error {
+		return r.syncWithoutRemove(op.Models)
+	})
+}
+
+func (r *HasManyRelation) syncWithoutRemove(models []mgm.Model) error {
+	if len(models) == 0 {
+		return nil
+	}
+	if err := r.checkAdding(extractIDs(models)); err != nil {
+		return err
+	}
+	if err := r.lockOwner(); err != nil {
+		return err
+	}
+	if err := r.checkOwnership(models); err != nil {
+		return err
+	}
+	if !r.ordered {
+		return r.syncBatch(models)
+	}
+
+	// Existing models keep their position, and we place
+	// the new models after the other related models.
+	positions, err := r.appendPositions(extractIDs(models))
+	if err != nil {
+		return err
+	}
+	return r.syncOrderedBatch(models, positions)
 }
 
 // Sync method sync the relations:
@@ -73,76 +260,107 @@ func (r *HasManyRelation) SyncWithoutRemove(docs interface{}) error {
 // items that are not in the provided list.
 // Use sync just when your 1-m model contains just few m mdoel. otherwise use SyncWithoutRemove
 func (r *HasManyRelation) Sync(docs interface{}) error {
-	if gutil.IsNil(docs) {
-		_, err := r.delete(nil)
+	op := &Op{Operation: OpSync, Models: r.toModels(docs)}
+	return r.run(op, func(op *Op) error {
+		return r.sync(op.Models)
+	})
+}
+
+func (r *HasManyRelation) sync(models []mgm.Model) error {
+	if err := r.checkCardinality(int64(len(models))); err != nil {
+		return err
+	}
+	if err := r.lockOwner(); err != nil {
 		return err
 	}
-	models := r.toModel(gutil.InterfaceToSlice(docs))
 	if len(models) == 0 {
 		_, err := r.delete(nil)
 		return err
 	}
-	for _, m := range models {
-		if err := callToBeforeSyncHooks(m); err != nil {
-			return err
-		}
-		_, err := mgm.Coll(r.related).UpdateOne(mgm.Ctx(), bson.M{f.ID: m.GetID()}, bson.M{o.Set: m}, &options.UpdateOptions{
-			Upsert: gutil.NewBool(true),
-		})
-		if err != nil {
-			return err
-		}
-		if err := callToAfterSyncHooks(m); err != nil {
+	if err := r.checkOwnership(models); err != nil {
+		return err
+	}
+
+	// We call to the Removing hooks of other models before writing
+	// any model, so they can veto the sync without a partial write.
+	var removing []mgm.Model
+	removeHooks := hasRemoveHooks(r.related)
+	if removeHooks {
+		var err error
+		if removing, err = r.loadRemoving(r.filterByRelation(extractIDs(models))); err != nil {
 			return err
 		}
 	}
-	// Delete All other models that are not in provided models.
-	_, err := r.delete(r.extractIDs(models))
-	return err
-}
-
-func (r *HasManyRelation) delete(exceptIDs []interface{}) (*mongo.DeleteResult, error) {
-	return mgm.Coll(r.related).DeleteMany(mgm.Ctx(), r.filterByRelation(exceptIDs))
-}
 
-func (r *HasManyRelation) filterByRelation(exceptIDs []interface{}) bson.M {
-	filter := bson.M{r.foreignKey: r.m.GetID()}
-	if len(exceptIDs) != 0 {
-		filter[f.ID] = bson.M{o.Nin: exceptIDs}
+	// In the ContinueOnError mode we remove other models even if
+	// some models failed, and then return their errors.
+	var syncErr error
+	if r.ordered {
+		syncErr = r.syncOrderedBatch(models, sequentialPositions(len(models)))
+	} else {
+		syncErr = r.syncBatch(models)
+	}
+	if syncErr != nil {
+		if _, ok := syncErr.(SyncErrors); !ok {
+			return syncErr
+		}
 	}
 
-	return filter
+	// Delete All other models that are not in provided models.
+	var err error
+	if removeHooks {
+		_, err = r.deleteLoaded(extractIDs(models), removing)
+	} else {
+		_, err = r.delete(extractIDs(models))
+	}
+	if err != nil {
+		return err
+	}
+	return syncErr
 }
 
-func (r *HasManyRelation) toModel(docs []interface{}) []mgm.Model {
-	models := make([]mgm.Model, len(docs))
-	for i, doc := range docs {
-		models[i] = doc.(mgm.Model)
+// syncBatch calls to the owner's batch hooks and syncs the models.
+// in the ContinueOnError mode it syncs all of the models and returns
+// the SyncErrors if some of them fail.
+func (r *HasManyRelation) syncBatch(models []mgm.Model) error {
+	if err := callToBeforeSyncBatchHooks(r.m, r.info(), models); err != nil {
+		return err
 	}
 
-	return models
-}
-
-func (r *HasManyRelation) extractIDs(models []mgm.Model) []interface{} {
-	ids := make([]interface{}, len(models))
+	synced := make([]mgm.Model, 0, len(models))
+	var errs SyncErrors
 	for i, m := range models {
-		ids[i] = m.GetID()
+		if err := r.syncModel(m); err != nil {
+			if !r.continueOnError {
+				return err
+			}
+			errs = append(errs, &ModelError{Index: i, ID: m.GetID(), Err: err})
+			continue
+		}
+		synced = append(synced, m)
 	}
-	return ids
-}
 
-// sortFieldToBsonD converts the string sort field to bson D.
-func (r *HasManyRelation) sortFieldToBsonD(field string) bson.D {
-	// Ascending order
-	order := 1
-	if field[0] == '-' {
-		order = -1
-		field = field[1:]
+	if err := callToAfterSyncBatchHooks(r.m, r.info(), synced); err != nil {
+		return err
 	}
+	if len(errs) != 0 {
+		return errs
+	}
+	return nil
+}
 
-	return bson.D{
-		{field, order},
+// toModels converts the slice of models to the list of mgm.Model.
+func (r *HasManyRelation) toModels(docs interface{}) []mgm.Model {
+	if gutil.IsNil(docs) {
+		return nil
 	}
+	slice := gutil.InterfaceToSlice(docs)
+	models := make([]mgm.Model, len(slice))
+	for i, doc := range slice {
+		models[i] = doc.(mgm.Model)
+	}
+
+	return models
 }
 
 // HasMany returns new instance of the "has many" relation ship.
@@ -153,8 +371,13 @@ func HasMany(model mgm.Model, related mgm.Model) *HasManyRelation {
 // HasManyWithOptions gets HasManyRelation options and returns new instance of it.
 func HasManyWithOptions(model mgm.Model, related mgm.Model, foreignKey string) *HasManyRelation {
 	return &HasManyRelation{
-		m:          model,
-		related:    related,
-		foreignKey: foreignKey,
+		relation: relation{
+			m:          model,
+			related:    related,
+			kind:       HasManyKind,
+			foreignKey: foreignKey,
+			mgmHooks:   defaultMgmHooks,
+			sort:       defaultHasManySort,
+		},
 	}
 }
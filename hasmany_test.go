@@ -1,6 +1,7 @@
 package mgmrel_test
 
 import (
+	"errors"
 	"fmt"
 	mgmrel "github.com/kamva/mgm-relation"
 	"github.com/kamva/mgm/v3"
@@ -408,3 +409,202 @@ func TestHasManyRelation_SyncWithoutRemove_Update_Does_Not_Affect_Other(t *testi
 	assert.Equal(t, unrelatedAuthor.DocID, foundAuthor.DocID)
 	assert.Equal(t, unrelatedAuthor.Name, foundAuthor.Name)
 }
+
+func TestHasManyRelation_Scope(t *testing.T) {
+	setupDefConnection()
+	resetCollection()
+	d, authors := insertHasManyRelation(t)
+
+	foundAuthors := make([]*DocAuthor, 0)
+	rel := mgmrel.HasMany(d, &DocAuthor{}).Scope(bson.M{"name": authors[0].Name})
+	require.NoError(t, rel.SimpleGet(&foundAuthors, 10))
+	require.Equal(t, 1, len(foundAuthors))
+	assert.Equal(t, authors[0].ID, foundAuthors[0].ID)
+
+	foundAuthors = make([]*DocAuthor, 0)
+	require.NoError(t, rel.WithoutScopes().SimpleGet(&foundAuthors, 10))
+	assert.Equal(t, len(authors), len(foundAuthors))
+}
+
+func TestHasManyRelation_DefaultSortAndProjection(t *testing.T) {
+	setupDefConnection()
+	resetCollection()
+	d, authors := insertHasManyRelation(t)
+
+	foundAuthors := make([]*DocAuthor, 0)
+	rel := mgmrel.HasMany(d, &DocAuthor{}).DefaultSort("name").Projection(bson.M{"name": 1})
+	require.NoError(t, rel.SimpleGet(&foundAuthors, 10))
+	require.Equal(t, len(authors), len(foundAuthors))
+	for i, author := range authors {
+		assert.Equal(t, author.ID, foundAuthors[i].ID)
+		assert.Equal(t, author.Name, foundAuthors[i].Name)
+		assert.True(t, foundAuthors[i].DocID.IsZero())
+	}
+}
+
+type SlugAuthor struct {
+	mgmrel.StringIDField `bson:",inline"`
+
+	DocID primitive.ObjectID `bson:"doc_id"`
+}
+
+type BatchDoc struct {
+	mgmrel.IDField `bson:",inline"`
+
+	MaxAuthors int `bson:"-"`
+	synced     []mgm.Model
+}
+
+func (d *BatchDoc) CollectionName() string {
+	return "docs"
+}
+
+func (d *BatchDoc) BeforeSyncBatch(info mgmrel.RelationInfo, related []mgm.Model) error {
+	if len(related) > d.MaxAuthors {
+		return errors.New("too many authors")
+	}
+	return nil
+}
+
+func (d *BatchDoc) AfterSyncBatch(info mgmrel.RelationInfo, related []mgm.Model) error {
+	d.synced = related
+	return nil
+}
+
+func TestHasManyRelation_ContinueOnError(t *testing.T) {
+	setupDefConnection()
+	resetCollection()
+	_, err := mgm.Coll(&SlugAuthor{}).DeleteMany(mgm.Ctx(), bson.M{})
+	require.NoError(t, err)
+
+	d := NewDoc("A", 12)
+	require.NoError(t, mgm.Coll(d).Create(d))
+
+	authors := []*SlugAuthor{{DocID: d.ID}, {DocID: d.ID}, {DocID: d.ID}}
+	authors[0].ID = "first"
+	authors[2].ID = "third"
+
+	err = mgmrel.HasMany(d, &SlugAuthor{}).SyncWithoutRemove(authors)
+	require.Equal(t, mgmrel.ErrEmptyID, err)
+
+	err = mgmrel.HasMany(d, &SlugAuthor{}).ContinueOnError(true).SyncWithoutRemove(authors)
+	syncErrs, ok := err.(mgmrel.SyncErrors)
+	require.True(t, ok)
+	require.Equal(t, 1, len(syncErrs))
+	assert.Equal(t, 1, syncErrs[0].Index)
+	assert.True(t, errors.Is(syncErrs[0], mgmrel.ErrEmptyID))
+
+	c, err := mgm.Coll(&SlugAuthor{}).CountDocuments(mgm.Ctx(), bson.M{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), c)
+}
+
+func TestHasManyRelation_SyncBatchHooks(t *testing.T) {
+	setupDefConnection()
+	resetCollection()
+
+	d := &BatchDoc{MaxAuthors: 1}
+	require.NoError(t, mgm.Coll(d).Create(d))
+
+	authors := []*DocAuthor{NewDocAuthor("B1", d.ID), NewDocAuthor("B2", d.ID)}
+	require.EqualError(t, mgmrel.HasMany(d, &DocAuthor{}).Sync(authors), "too many authors")
+
+	c, err := mgm.Coll(&DocAuthor{}).CountDocuments(mgm.Ctx(), bson.M{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), c)
+
+	require.NoError(t, mgmrel.HasMany(d, &DocAuthor{}).Sync(authors[:1]))
+	assert.Equal(t, []mgm.Model{authors[0]}, d.synced)
+}
+
+func TestHasManyRelation_Sync_ForeignOwnership(t *testing.T) {
+	setupDefConnection()
+	resetCollection()
+	d, authors := insertHasManyRelation(t)
+
+	other := NewDoc("Other", 20)
+	require.NoError(t, mgm.Coll(other).Create(other))
+
+	err := mgmrel.HasMany(other, &DocAuthor{}).SyncWithoutRemove(authors)
+	require.True(t, errors.Is(err, mgmrel.ErrForeignOwnership))
+	ownershipErr, ok := err.(*mgmrel.ForeignOwnershipError)
+	require.True(t, ok)
+	assert.ElementsMatch(t, []interface{}{authors[0].ID, authors[1].ID}, ownershipErr.IDs)
+
+	c, err := mgm.Coll(&DocAuthor{}).CountDocuments(mgm.Ctx(), bson.M{"doc_id": d.ID})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), c)
+}
+
+func TestHasManyRelation_Sync_AllowReparent(t *testing.T) {
+	setupDefConnection()
+	resetCollection()
+	_, authors := insertHasManyRelation(t)
+
+	other := NewDoc("Other", 20)
+	require.NoError(t, mgm.Coll(other).Create(other))
+
+	for _, author := range authors {
+		author.DocID = other.ID
+	}
+	require.NoError(t, mgmrel.HasMany(other, &DocAuthor{}).AllowReparent().Sync(authors))
+
+	c, err := mgm.Coll(&DocAuthor{}).CountDocuments(mgm.Ctx(), bson.M{"doc_id": other.ID})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), c)
+}
+
+func TestHasManyRelation_Pluck(t *testing.T) {
+	setupDefConnection()
+	resetCollection()
+	d, authors := insertHasManyRelation(t)
+
+	names := make([]string, 0)
+	require.NoError(t, mgmrel.HasMany(d, &DocAuthor{}).DefaultSort("name").Pluck("name", &names))
+	assert.Equal(t, []string{authors[0].Name, authors[1].Name}, names)
+}
+
+func TestHasManyRelation_Distinct(t *testing.T) {
+	setupDefConnection()
+	resetCollection()
+	d, authors := insertHasManyRelation(t)
+	require.NoError(t, mgmrel.HasMany(d, &DocAuthor{}).SyncWithoutRemove([]*DocAuthor{NewDocAuthor(authors[0].Name, d.ID)}))
+
+	names, err := mgmrel.HasMany(d, &DocAuthor{}).Distinct("name")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []interface{}{authors[0].Name, authors[1].Name}, names)
+}
+
+func TestHasManyRelation_IDs(t *testing.T) {
+	setupDefConnection()
+	resetCollection()
+	d, authors := insertHasManyRelation(t)
+
+	ids, err := mgmrel.HasMany(d, &DocAuthor{}).IDs()
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{authors[1].ID, authors[0].ID}, ids)
+}
+
+func TestHasManyRelation_Pluck_InvalidResults(t *testing.T) {
+	names := make([]string, 0)
+	require.Error(t, mgmrel.HasMany(NewDoc("A", 12), &DocAuthor{}).Pluck("name", names))
+}
+
+func TestHasManyRelation_QueryHelpers_Middleware(t *testing.T) {
+	denied := errors.New("denied")
+	var ops []mgmrel.Operation
+	rel := mgmrel.HasMany(NewDoc("A", 12), &DocAuthor{}).Use(func(next mgmrel.SyncFunc) mgmrel.SyncFunc {
+		return func(op *mgmrel.Op) error {
+			ops = append(ops, op.Operation)
+			return denied
+		}
+	})
+
+	names := make([]string, 0)
+	assert.Equal(t, denied, rel.Pluck("name", &names))
+	_, err := rel.Distinct("name")
+	assert.Equal(t, denied, err)
+	_, err = rel.IDs()
+	assert.Equal(t, denied, err)
+	assert.Equal(t, []mgmrel.Operation{mgmrel.OpGet, mgmrel.OpGet, mgmrel.OpGet}, ops)
+}
@@ -4,23 +4,111 @@ import (
 	"github.com/kamva/gutil"
 	"github.com/kamva/mgm/v3"
 	f "github.com/kamva/mgm/v3/field"
-	o "github.com/kamva/mgm/v3/operator"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type HasOneRelation struct {
-	m       mgm.Model
-	related mgm.Model
-	// foreignKey uses in filters.
-	foreignKey string
+	relation
+
+	// updateInPlace reuses the existing related model's id on sync.
+	updateInPlace bool
+}
+
+// Scope sets the default filter scope of the relation. it applies
+// on the Get method. e.g `bson.M{"status": "active"}`
+func (r *HasOneRelation) Scope(scope bson.M) *HasOneRelation {
+	r.scope = scope
+	return r
+}
+
+// WithoutScopes returns copy of the relation that does not apply
+// the relation's scope on the Get method.
+func (r *HasOneRelation) WithoutScopes() *HasOneRelation {
+	cp := *r
+	cp.withoutScopes = true
+	return &cp
+}
+
+// DefaultSort sets the default sort field of the relation. it's useful
+// when there are more than one related model in the DB.
+// you can sort descending by adding a `-` to the sort field. e.g `-created_at`
+func (r *HasOneRelation) DefaultSort(sort string) *HasOneRelation {
+	r.sort = sort
+	return r
+}
+
+// Projection sets the default projection of the relation.
+func (r *HasOneRelation) Projection(projection interface{}) *HasOneRelation {
+	r.projection = projection
+	return r
+}
+
+// NamingStrategy sets the relation's foreign key using the provided
+// naming strategy instead of the global naming strategy.
+func (r *HasOneRelation) NamingStrategy(s NamingStrategy) *HasOneRelation {
+	r.foreignKey = s.ForeignKey(r.m)
+	return r
+}
+
+// MgmHooks sets whether the relation dispatches the mgm model hooks
+// (CreatingHook, UpdatingHook, SavingHook, CreatedHook,...) on sync.
+// insert and update are distinguished by checking the model's existence.
+func (r *HasOneRelation) MgmHooks(enable bool) *HasOneRelation {
+	r.mgmHooks = enable
+	return r
+}
+
+// Use adds middlewares that wrap the relation's operations.
+func (r *HasOneRelation) Use(mw ...Middleware) *HasOneRelation {
+	r.middlewares = append(r.middlewares, mw...)
+	return r
+}
+
+// Info returns the relation's metadata.
+func (r *HasOneRelation) Info() RelationInfo {
+	return r.info()
+}
+
+// TrackChanges sets whether the relation tracks changes of the models
+// that it loads by the get methods. in this mode, sync methods just write
+// the changed fields of the loaded models (using `$set` and `$unset`) and
+// skip the models that are not changed.
+func (r *HasOneRelation) TrackChanges(enable bool) *HasOneRelation {
+	r.trackChanges = enable
+	return r
+}
+
+// AllowReparent lets sync methods move models that belong to another
+// owner to this relation's owner. by default sync methods return the
+// ForeignOwnershipError for those models.
+func (r *HasOneRelation) AllowReparent() *HasOneRelation {
+	r.allowReparent = true
+	return r
+}
+
+// UpdateInPlace sets whether sync updates the existing related model in
+// place. in this mode sync finds the existing related model by the foreign
+// key and reuses its id for the provided model, instead of removing it
+// and inserting the new model.
+func (r *HasOneRelation) UpdateInPlace(enable bool) *HasOneRelation {
+	r.updateInPlace = enable
+	return r
 }
 
-// SimpleGet method get the single related model.
+// Get method get the single related model.
 // if not found, returns the Mongo Go driver not found error.
 func (r *HasOneRelation) Get(m mgm.Model) error {
-	return mgm.Coll(r.related).First(r.filterByRelation(nil), m)
+	op := &Op{Operation: OpGet, Filter: r.scopedFilter(), Results: m}
+	return r.run(op, func(op *Op) error {
+		if err := mgm.Coll(r.related).First(op.Filter, op.Results.(mgm.Model), r.findOneOptions()); err != nil {
+			return err
+		}
+		if r.trackChanges {
+			return r.takeSnapshots(op.Results)
+		}
+		return nil
+	})
 }
 
 // Sync method sync the relations:
@@ -28,36 +116,131 @@ func (r *HasOneRelation) Get(m mgm.Model) error {
 // If provided model is not nil: sync it.
 // insert new model, otherwise upsert provided model.
 func (r *HasOneRelation) Sync(model mgm.Model) error {
+	op := &Op{Operation: OpSync}
+	if !gutil.IsNil(model) {
+		op.Models = []mgm.Model{model}
+	}
+	return r.run(op, func(op *Op) error {
+		if len(op.Models) == 0 {
+			return r.sync(nil)
+		}
+		return r.sync(op.Models[0])
+	})
+}
+
+// FirstOrCreate gets the related model. if it does not exist,
+// it syncs the provided model as the related model. it returns
+// ErrRelatedExists if the related model exists, but it's out of
+// the relation's scope.
+func (r *HasOneRelation) FirstOrCreate(model mgm.Model) error {
+	err := r.Get(model)
+	if err != mongo.ErrNoDocuments {
+		return err
+	}
+	if err := r.checkNotRelated(); err != nil {
+		return err
+	}
+	return r.Sync(model)
+}
+
+// UpdateOrCreate updates the existing related model with the provided
+// model, reusing the existing model's id. if there is no related model,
+// it creates the provided model.
+func (r *HasOneRelation) UpdateOrCreate(model mgm.Model) error {
+	cp := *r
+	cp.updateInPlace = true
+	return cp.Sync(model)
+}
+
+func (r *HasOneRelation) sync(model mgm.Model) error {
+	if err := r.lockOwner(); err != nil {
+		return err
+	}
 	if gutil.IsNil(model) {
 		_, err := r.delete(nil)
 		return err
 	}
-	if err := callToBeforeSyncHooks(model); err != nil {
+	if r.updateInPlace {
+		if err := r.reuseExistingID(model); err != nil {
+			return err
+		}
+	}
+	if err := r.checkOwnership([]mgm.Model{model}); err != nil {
+		return err
+	}
+	unchanged, err := r.unchanged(model)
+	if err != nil {
+		return err
+	}
+	if unchanged {
+		_, err := r.delete(model.GetID())
+		return err
+	}
+	if err := r.beforeSync(model); err != nil {
 		return err
 	}
 
-	_, err := r.delete(model.GetID())
-	upsert := true
-	_, err = mgm.Coll(r.related).UpdateOne(mgm.Ctx(), bson.M{f.ID: model.GetID()}, bson.M{o.Set: model}, &options.UpdateOptions{
-		Upsert: &upsert,
-	})
+	if _, err := r.delete(model.GetID()); err != nil {
+		return err
+	}
+	res, err := r.write(model)
 	if err != nil {
 		return err
 	}
 
-	return callToAfterSyncHooks(model)
+	return r.afterSync(model, res)
 }
 
-func (r *HasOneRelation) delete(exceptID interface{}) (*mongo.DeleteResult, error) {
-	return mgm.Coll(r.related).DeleteMany(mgm.Ctx(), r.filterByRelation(exceptID))
+// reuseExistingID sets id of the existing related model on the model.
+// if the model is versioned and it's not the existing model itself, it
+// also gets the existing model's version, so replacing it in place
+// does not conflict.
+func (r *HasOneRelation) reuseExistingID(model mgm.Model) error {
+	existing := struct {
+		ID      interface{} `bson:"_id"`
+		Version int64       `bson:"version"`
+	}{}
+	opts := r.findOneOptions().SetProjection(bson.M{f.ID: 1, VersionFieldName: 1})
+	err := mgm.Coll(r.related).FindOne(mgm.Ctx(), r.filterByRelation(nil), opts).Decode(&existing)
+	if err == mongo.ErrNoDocuments {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if versioned, ok := model.(Versioned); ok {
+		same, err := sameID(model.GetID(), existing.ID)
+		if err != nil {
+			return err
+		}
+		if !same {
+			versioned.SetVersion(existing.Version)
+		}
+	}
+	model.SetID(existing.ID)
+	return nil
 }
 
-func (r *HasOneRelation) filterByRelation(exceptionID interface{}) bson.M {
-	filter := bson.M{r.foreignKey: r.m.GetID()}
-	if !gutil.IsNil(exceptionID) {
-		filter[f.ID] = bson.M{o.Ne: exceptionID}
+// sameID checks whether the ids are equal.
+func sameID(a, b interface{}) (bool, error) {
+	aKey, err := idKey(a)
+	if err != nil {
+		return false, err
+	}
+	bKey, err := idKey(b)
+	if err != nil {
+		return false, err
+	}
+	return aKey == bKey, nil
+}
+
+func (r *HasOneRelation) delete(exceptID interface{}) (*mongo.DeleteResult, error) {
+	var exceptIDs []interface{}
+	if !gutil.IsNil(exceptID) {
+		exceptIDs = []interface{}{exceptID}
 	}
-	return filter
+	return r.relation.delete(exceptIDs)
 }
 
 // HasOne returns new instance of the "has one" relation ship.
@@ -68,8 +251,13 @@ func HasOne(model mgm.Model, related mgm.Model) *HasOneRelation {
 // HasOneByOptions gets HasOneRelation options and returns new instance of it.
 func HasOneByOptions(model mgm.Model, related mgm.Model, foreignKey string) *HasOneRelation {
 	return &HasOneRelation{
-		m:          model,
-		related:    related,
-		foreignKey: foreignKey,
+		relation: relation{
+			m:          model,
+			related:    related,
+			kind:       HasOneKind,
+			foreignKey: foreignKey,
+			eagerLimit: 1,
+			mgmHooks:   defaultMgmHooks,
+		},
 	}
 }
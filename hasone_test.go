@@ -1,6 +1,7 @@
 package mgmrel_test
 
 import (
+	"errors"
 	mgmrel "github.com/kamva/mgm-relation"
 	"github.com/kamva/mgm/v3"
 	f "github.com/kamva/mgm/v3/field"
@@ -197,3 +198,107 @@ func TestHasOneRelation_Sync_Update_Does_Not_Affect_Other(t *testing.T) {
 	assert.Equal(t, unrelatedAuthor.DocID, foundAuthor.DocID)
 	assert.Equal(t, unrelatedAuthor.Name, foundAuthor.Name)
 }
+
+func TestHasOneRelation_Get_Scope(t *testing.T) {
+	setupDefConnection()
+	resetCollection()
+	d, author := insertHasOneRelation(t)
+
+	foundAuthor := &DocAuthor{}
+	rel := mgmrel.HasOne(d, &DocAuthor{}).Scope(bson.M{"name": "unknown"})
+	require.Equal(t, mongo.ErrNoDocuments, rel.Get(foundAuthor))
+
+	require.NoError(t, rel.WithoutScopes().Get(foundAuthor))
+	require.Equal(t, author.ID, foundAuthor.ID)
+}
+
+func TestHasOneRelation_UpdateInPlace(t *testing.T) {
+	setupDefConnection()
+	resetCollection()
+	d, author := insertHasOneRelation(t)
+
+	newAuthor := NewDocAuthor("Omid", d.ID)
+	require.NoError(t, mgmrel.HasOne(d, &DocAuthor{}).UpdateInPlace(true).Sync(newAuthor))
+	require.Equal(t, author.ID, newAuthor.ID)
+
+	results := make([]*DocAuthor, 0)
+	require.NoError(t, mgm.Coll(&DocAuthor{}).SimpleFind(&results, bson.M{}))
+	require.Equal(t, 1, len(results))
+	assert.Equal(t, author.ID, results[0].ID)
+	assert.Equal(t, "Omid", results[0].Name)
+}
+
+func TestHasOneRelation_UpdateOrCreate(t *testing.T) {
+	setupDefConnection()
+	resetCollection()
+	d := NewDoc("Ali", 12)
+	require.NoError(t, mgm.Coll(d).Create(d))
+
+	rel := mgmrel.HasOne(d, &DocAuthor{})
+	author := NewDocAuthor("Reza", d.ID)
+	require.NoError(t, rel.UpdateOrCreate(author))
+	require.False(t, author.ID.IsZero())
+
+	newAuthor := NewDocAuthor("Omid", d.ID)
+	require.NoError(t, rel.UpdateOrCreate(newAuthor))
+	assert.Equal(t, author.ID, newAuthor.ID)
+
+	c, err := mgm.Coll(&DocAuthor{}).CountDocuments(mgm.Ctx(), bson.M{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), c)
+}
+
+func TestHasOneRelation_UpdateInPlace_Versioned(t *testing.T) {
+	setupDefConnection()
+	resetCollection()
+	resetVersionedAuthors(t)
+	d := NewDoc("Ali", 12)
+	require.NoError(t, mgm.Coll(d).Create(d))
+
+	rel := mgmrel.HasOne(d, &VersionedAuthor{})
+	author := &VersionedAuthor{Name: "Reza", DocID: d.ID}
+	require.NoError(t, rel.UpdateOrCreate(author))
+	require.Equal(t, int64(1), author.Version)
+
+	newAuthor := &VersionedAuthor{Name: "Omid", DocID: d.ID}
+	require.NoError(t, rel.UpdateInPlace(true).Sync(newAuthor))
+	assert.Equal(t, author.ID, newAuthor.ID)
+	assert.Equal(t, int64(2), newAuthor.Version)
+
+	// A stale copy of the existing model still conflicts.
+	author.Name = "stale"
+	err := rel.UpdateInPlace(true).Sync(author)
+	assert.True(t, errors.Is(err, mgmrel.ErrConcurrentModification))
+}
+
+func TestHasOneRelation_FirstOrCreate(t *testing.T) {
+	setupDefConnection()
+	resetCollection()
+	d := NewDoc("Ali", 12)
+	require.NoError(t, mgm.Coll(d).Create(d))
+
+	rel := mgmrel.HasOne(d, &DocAuthor{})
+	author := NewDocAuthor("Reza", d.ID)
+	require.NoError(t, rel.FirstOrCreate(author))
+	require.False(t, author.ID.IsZero())
+
+	found := NewDocAuthor("Omid", d.ID)
+	require.NoError(t, rel.FirstOrCreate(found))
+	assert.Equal(t, author.ID, found.ID)
+	assert.Equal(t, "Reza", found.Name)
+}
+
+func TestHasOneRelation_FirstOrCreate_OutOfScope(t *testing.T) {
+	setupDefConnection()
+	resetCollection()
+	d, author := insertHasOneRelation(t)
+
+	rel := mgmrel.HasOne(d, &DocAuthor{}).Scope(bson.M{"name": "Omid"})
+	err := rel.FirstOrCreate(NewDocAuthor("Omid", d.ID))
+	assert.Equal(t, mgmrel.ErrRelatedExists, err)
+
+	// The out of scope model is not replaced.
+	found := &DocAuthor{}
+	require.NoError(t, mgmrel.HasOne(d, &DocAuthor{}).Get(found))
+	assert.Equal(t, author.ID, found.ID)
+}
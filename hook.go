@@ -1,6 +1,9 @@
 package mgmrel
 
-import "github.com/kamva/mgm/v3"
+import (
+	"github.com/kamva/mgm/v3"
+	"go.mongodb.org/mongo-driver/mongo"
+)
 
 // SyncingHook is the interface to implement hook to call before sync your model.
 type SyncingHook interface {
@@ -12,7 +15,42 @@ type SyncedHook interface {
 	Synced() error
 }
 
+// BeforeSyncBatchHook is the interface to implement by the owner model
+// to validate the whole list of related models before a "has many"
+// relation syncs them. if return error, we cancel sync and return
+// that error to the caller.
+type BeforeSyncBatchHook interface {
+	BeforeSyncBatch(info RelationInfo, related []mgm.Model) error
+}
+
+// AfterSyncBatchHook is the interface to implement by the owner model
+// to call after a "has many" relation synced the related models. it
+// gets the models that synced successfully.
+type AfterSyncBatchHook interface {
+	AfterSyncBatch(info RelationInfo, related []mgm.Model) error
+}
+
+// RemovingHook is the interface to implement hook to call before the
+// relation removes your model (e.g on Sync). if it returns error,
+// we cancel the removal and return that error to the caller. the
+// removal may still fail after it, so it must not have side effects,
+// do the cleanup in the RemovedHook.
+type RemovingHook interface {
+	Removing() error
+}
+
+// RemovedHook is the interface to implement hook to call after the
+// relation removed your model.
+type RemovedHook interface {
+	Removed() error
+}
+
 func callToBeforeSyncHooks(m mgm.Model) error {
+	// Let the id field know the model's sequence name before it generates the id.
+	if binder, ok := m.(sequenceBinder); ok {
+		binder.bindSequence(collectionName(m))
+	}
+
 	if hook, ok := m.(SyncingHook); ok {
 		return hook.Syncing()
 	}
@@ -25,3 +63,98 @@ func callToAfterSyncHooks(m mgm.Model) error {
 	}
 	return nil
 }
+
+func callToBeforeSyncBatchHooks(owner mgm.Model, info RelationInfo, related []mgm.Model) error {
+	if hook, ok := owner.(BeforeSyncBatchHook); ok {
+		return hook.BeforeSyncBatch(info, related)
+	}
+	return nil
+}
+
+func callToAfterSyncBatchHooks(owner mgm.Model, info RelationInfo, related []mgm.Model) error {
+	if hook, ok := owner.(AfterSyncBatchHook); ok {
+		return hook.AfterSyncBatch(info, related)
+	}
+	return nil
+}
+
+func hasRemoveHooks(m mgm.Model) bool {
+	_, removing := m.(RemovingHook)
+	_, removed := m.(RemovedHook)
+	return removing || removed
+}
+
+func callToBeforeRemoveHooks(m mgm.Model) error {
+	if hook, ok := m.(RemovingHook); ok {
+		return hook.Removing()
+	}
+	return nil
+}
+
+func callToAfterRemoveHooks(m mgm.Model) error {
+	if hook, ok := m.(RemovedHook); ok {
+		return hook.Removed()
+	}
+	return nil
+}
+
+var defaultMgmHooks = false
+
+// UseMgmHooks sets whether new relations dispatch the mgm model hooks
+// (CreatingHook, UpdatingHook, SavingHook, CreatedHook,...) on sync.
+// you can change it per relation by the relation's MgmHooks method.
+func UseMgmHooks(enable bool) {
+	defaultMgmHooks = enable
+}
+
+func callToMgmBeforeCreateHooks(m mgm.Model) error {
+	if hook, ok := m.(mgm.CreatingHook); ok {
+		if err := hook.Creating(); err != nil {
+			return err
+		}
+	}
+
+	if hook, ok := m.(mgm.SavingHook); ok {
+		return hook.Saving()
+	}
+	return nil
+}
+
+func callToMgmBeforeUpdateHooks(m mgm.Model) error {
+	if hook, ok := m.(mgm.UpdatingHook); ok {
+		if err := hook.Updating(); err != nil {
+			return err
+		}
+	}
+
+	if hook, ok := m.(mgm.SavingHook); ok {
+		return hook.Saving()
+	}
+	return nil
+}
+
+func callToMgmAfterCreateHooks(m mgm.Model) error {
+	if hook, ok := m.(mgm.CreatedHook); ok {
+		if err := hook.Created(); err != nil {
+			return err
+		}
+	}
+
+	if hook, ok := m.(mgm.SavedHook); ok {
+		return hook.Saved()
+	}
+	return nil
+}
+
+func callToMgmAfterUpdateHooks(res *mongo.UpdateResult, m mgm.Model) error {
+	if hook, ok := m.(mgm.UpdatedHook); ok {
+		if err := hook.Updated(res); err != nil {
+			return err
+		}
+	}
+
+	if hook, ok := m.(mgm.SavedHook); ok {
+		return hook.Saved()
+	}
+	return nil
+}
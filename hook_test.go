@@ -0,0 +1,211 @@
+package mgmrel_test
+
+import (
+	"errors"
+	mgmrel "github.com/kamva/mgm-relation"
+	"github.com/kamva/mgm/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"testing"
+)
+
+type HookedAuthor struct {
+	mgmrel.IDField `bson:",inline"`
+
+	Name  string             `bson:"name"`
+	DocID primitive.ObjectID `bson:"doc_id"`
+
+	calls []string
+}
+
+func (a *HookedAuthor) Creating() error {
+	a.calls = append(a.calls, "creating")
+	return nil
+}
+
+func (a *HookedAuthor) Created() error {
+	a.calls = append(a.calls, "created")
+	return nil
+}
+
+func (a *HookedAuthor) Updating() error {
+	a.calls = append(a.calls, "updating")
+	return nil
+}
+
+func (a *HookedAuthor) Updated(_ *mongo.UpdateResult) error {
+	a.calls = append(a.calls, "updated")
+	return nil
+}
+
+func (a *HookedAuthor) Saving() error {
+	a.calls = append(a.calls, "saving")
+	return nil
+}
+
+func (a *HookedAuthor) Saved() error {
+	a.calls = append(a.calls, "saved")
+	return nil
+}
+
+func resetHookedAuthors(t *testing.T) {
+	_, err := mgm.Coll(&HookedAuthor{}).DeleteMany(mgm.Ctx(), bson.M{})
+	require.NoError(t, err)
+}
+
+func TestMgmHooks_Disabled(t *testing.T) {
+	setupDefConnection()
+	resetCollection()
+	resetHookedAuthors(t)
+
+	d := NewDoc("A", 12)
+	require.NoError(t, mgm.Coll(d).Create(d))
+
+	author := &HookedAuthor{Name: "B1", DocID: d.ID}
+	require.NoError(t, mgmrel.HasMany(d, &HookedAuthor{}).Sync([]*HookedAuthor{author}))
+	assert.Empty(t, author.calls)
+}
+
+func TestMgmHooks_InsertAndUpdate(t *testing.T) {
+	setupDefConnection()
+	resetCollection()
+	resetHookedAuthors(t)
+
+	d := NewDoc("A", 12)
+	require.NoError(t, mgm.Coll(d).Create(d))
+
+	author := &HookedAuthor{Name: "B1", DocID: d.ID}
+	rel := mgmrel.HasMany(d, &HookedAuthor{}).MgmHooks(true)
+	require.NoError(t, rel.SyncWithoutRemove([]*HookedAuthor{author}))
+	assert.Equal(t, []string{"creating", "saving", "created", "saved"}, author.calls)
+
+	author.calls = nil
+	author.Name = "B2"
+	require.NoError(t, rel.SyncWithoutRemove([]*HookedAuthor{author}))
+	assert.Equal(t, []string{"updating", "saving", "updated", "saved"}, author.calls)
+}
+
+func TestMgmHooks_HasOne(t *testing.T) {
+	setupDefConnection()
+	resetCollection()
+	resetHookedAuthors(t)
+
+	d := NewDoc("A", 12)
+	require.NoError(t, mgm.Coll(d).Create(d))
+
+	author := &HookedAuthor{Name: "B1", DocID: d.ID}
+	require.NoError(t, mgmrel.HasOne(d, &HookedAuthor{}).MgmHooks(true).Sync(author))
+	assert.Equal(t, []string{"creating", "saving", "created", "saved"}, author.calls)
+}
+
+var removedAuthors []string
+
+type RemovableAuthor struct {
+	mgmrel.IDField `bson:",inline"`
+
+	Name  string             `bson:"name"`
+	DocID primitive.ObjectID `bson:"doc_id"`
+}
+
+func (a *RemovableAuthor) Removing() error {
+	if a.Name == "locked" {
+		return errors.New("locked author can not be removed")
+	}
+	return nil
+}
+
+func (a *RemovableAuthor) Removed() error {
+	removedAuthors = append(removedAuthors, a.Name)
+	return nil
+}
+
+func insertRemovableAuthors(t *testing.T, names ...string) (*Doc, []*RemovableAuthor) {
+	_, err := mgm.Coll(&RemovableAuthor{}).DeleteMany(mgm.Ctx(), bson.M{})
+	require.NoError(t, err)
+	removedAuthors = nil
+
+	d := NewDoc("A", 12)
+	require.NoError(t, mgm.Coll(d).Create(d))
+
+	authors := make([]*RemovableAuthor, len(names))
+	for i, name := range names {
+		authors[i] = &RemovableAuthor{Name: name, DocID: d.ID}
+	}
+	require.NoError(t, mgmrel.HasMany(d, &RemovableAuthor{}).Sync(authors))
+	return d, authors
+}
+
+func TestRemoveHooks_HasManySync(t *testing.T) {
+	setupDefConnection()
+	resetCollection()
+	d, authors := insertRemovableAuthors(t, "B1", "B2", "B3")
+
+	require.NoError(t, mgmrel.HasMany(d, &RemovableAuthor{}).Sync(authors[:1]))
+	assert.ElementsMatch(t, []string{"B2", "B3"}, removedAuthors)
+
+	c, err := mgm.Coll(&RemovableAuthor{}).CountDocuments(mgm.Ctx(), bson.M{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), c)
+}
+
+func TestRemoveHooks_HasManySync_MiddlewareFilter(t *testing.T) {
+	setupDefConnection()
+	resetCollection()
+	d, authors := insertRemovableAuthors(t, "B1", "B2", "B3")
+
+	rel := mgmrel.HasMany(d, &RemovableAuthor{}).Use(func(next mgmrel.SyncFunc) mgmrel.SyncFunc {
+		return func(op *mgmrel.Op) error {
+			if op.Operation == mgmrel.OpDelete {
+				op.Filter = bson.M{"$and": bson.A{op.Filter, bson.M{"name": bson.M{"$ne": "B3"}}}}
+			}
+			return next(op)
+		}
+	})
+	require.NoError(t, rel.Sync(authors[:1]))
+	assert.Equal(t, []string{"B2"}, removedAuthors)
+
+	c, err := mgm.Coll(&RemovableAuthor{}).CountDocuments(mgm.Ctx(), bson.M{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), c)
+}
+
+func TestRemoveHooks_Veto(t *testing.T) {
+	setupDefConnection()
+	resetCollection()
+	d, _ := insertRemovableAuthors(t, "B1", "locked")
+
+	require.Error(t, mgmrel.HasMany(d, &RemovableAuthor{}).Sync(nil))
+	assert.Empty(t, removedAuthors)
+
+	c, err := mgm.Coll(&RemovableAuthor{}).CountDocuments(mgm.Ctx(), bson.M{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), c)
+}
+
+func TestRemoveHooks_VetoBeforeWrite(t *testing.T) {
+	setupDefConnection()
+	resetCollection()
+	d, authors := insertRemovableAuthors(t, "B1", "locked")
+
+	authors[0].Name = "B1-updated"
+	newAuthor := &RemovableAuthor{Name: "B3", DocID: d.ID}
+	require.Error(t, mgmrel.HasMany(d, &RemovableAuthor{}).Sync([]*RemovableAuthor{authors[0], newAuthor}))
+
+	// The veto leaves the relation as it was.
+	names := make([]string, 0)
+	require.NoError(t, mgmrel.HasMany(d, &RemovableAuthor{}).DefaultSort("name").Pluck("name", &names))
+	assert.Equal(t, []string{"B1", "locked"}, names)
+}
+
+func TestRemoveHooks_HasOneSync(t *testing.T) {
+	setupDefConnection()
+	resetCollection()
+	d, _ := insertRemovableAuthors(t, "B1")
+
+	newAuthor := &RemovableAuthor{Name: "B2", DocID: d.ID}
+	require.NoError(t, mgmrel.HasOne(d, &RemovableAuthor{}).Sync(newAuthor))
+	assert.Equal(t, []string{"B1"}, removedAuthors)
+}
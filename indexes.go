@@ -0,0 +1,150 @@
+package mgmrel
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kamva/mgm/v3"
+	o "github.com/kamva/mgm/v3/operator"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Index is an index that a relation needs on its related collection.
+type Index struct {
+	Collection string
+	Keys       bson.D
+	Unique     bool
+}
+
+func (i Index) String() string {
+	return fmt.Sprintf("%s %v (unique: %t)", i.Collection, i.Keys, i.Unique)
+}
+
+// index returns the index that the relation needs: unique foreign key
+// index for "has one" relations, and the foreign key index compound with
+// the default sort field for "has many" relations.
+func (r *relation) index() Index {
+	idx := Index{
+		Collection: collectionName(r.related),
+		Keys:       bson.D{{Key: r.foreignKey, Value: 1}},
+	}
+
+	switch r.kind {
+	case HasOneKind:
+		idx.Unique = true
+	case HasManyKind:
+		if r.sort != "" {
+			idx.Keys = append(idx.Keys, sortFieldToBsonD(r.sort)...)
+		}
+	}
+	return idx
+}
+
+// MissingIndexes returns the relation's indexes that do not exist.
+func (r *relation) MissingIndexes(ctx context.Context) ([]Index, error) {
+	idx := r.index()
+	exists, err := indexExists(ctx, mgm.Coll(r.related).Collection, idx)
+	if err != nil || exists {
+		return nil, err
+	}
+	return []Index{idx}, nil
+}
+
+// EnsureIndexes creates the relation's indexes if they do not exist.
+// the "has one" relations get unique index on the foreign key (just
+// for documents that their foreign key has the owner id's type, so
+// null foreign keys do not conflict), and the "has many" relations
+// get index on the foreign key compound with the default sort field.
+func (r *relation) EnsureIndexes(ctx context.Context) error {
+	missing, err := r.MissingIndexes(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, idx := range missing {
+		model := mongo.IndexModel{Keys: idx.Keys}
+		if idx.Unique {
+			model.Options = options.Index().
+				SetUnique(true).
+				SetPartialFilterExpression(r.foreignKeyTypeFilter())
+		}
+		if _, err := mgm.Coll(r.related).Indexes().CreateOne(ctx, model); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// foreignKeyTypeFilter returns filter of the documents that their foreign
+// key has type of the owner's id. if we can not find the type, it returns
+// filter of the documents that have the foreign key.
+func (r *relation) foreignKeyTypeFilter() bson.M {
+	t, _, err := bson.MarshalValue(r.m.GetID())
+	if err != nil || t == bsontype.Null || t == bsontype.Undefined {
+		return bson.M{r.foreignKey: bson.M{o.Exists: true}}
+	}
+	return bson.M{r.foreignKey: bson.M{o.Type: int32(t)}}
+}
+
+// indexExists checks whether the collection has the index.
+func indexExists(ctx context.Context, coll *mongo.Collection, idx Index) (bool, error) {
+	const namespaceNotFoundCode = 26
+
+	cur, err := coll.Indexes().List(ctx)
+	if err != nil {
+		if e, ok := err.(mongo.CommandError); ok && e.Code == namespaceNotFoundCode {
+			return false, nil
+		}
+		return false, err
+	}
+	defer cur.Close(ctx)
+
+	for cur.Next(ctx) {
+		existing := struct {
+			Key    bson.D `bson:"key"`
+			Unique bool   `bson:"unique"`
+		}{}
+		if err := cur.Decode(&existing); err != nil {
+			return false, err
+		}
+		if existing.Unique == idx.Unique && sameIndexKeys(existing.Key, idx.Keys) {
+			return true, nil
+		}
+	}
+	return false, cur.Err()
+}
+
+// sameIndexKeys checks whether both index keys are the same.
+func sameIndexKeys(a, b bson.D) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Key != b[i].Key || fmt.Sprint(a[i].Value) != fmt.Sprint(b[i].Value) {
+			return false
+		}
+	}
+	return true
+}
+
+// EnsureAllIndexes ensures indexes of all registered relations. in the dry-run
+// mode it does not create any index. it returns the indexes that were missing.
+func EnsureAllIndexes(ctx context.Context, dryRun bool) ([]Index, error) {
+	var missing []Index
+	for _, rel := range registeredRelations() {
+		relMissing, err := rel.MissingIndexes(ctx)
+		if err != nil {
+			return missing, err
+		}
+		if !dryRun && len(relMissing) != 0 {
+			if err := rel.EnsureIndexes(ctx); err != nil {
+				return missing, err
+			}
+		}
+		missing = append(missing, relMissing...)
+	}
+	return missing, nil
+}
@@ -0,0 +1,93 @@
+package mgmrel_test
+
+import (
+	mgmrel "github.com/kamva/mgm-relation"
+	"github.com/kamva/mgm/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"testing"
+)
+
+type IndexedProfile struct {
+	mgmrel.IDField `bson:",inline"`
+
+	DocID primitive.ObjectID `bson:"doc_id"`
+}
+
+type IndexedAuthor struct {
+	mgmrel.IDField `bson:",inline"`
+
+	DocID primitive.ObjectID `bson:"doc_id"`
+}
+
+func dropIndexes(t *testing.T, m mgm.Model) {
+	require.NoError(t, mgm.Coll(m).Drop(mgm.Ctx()))
+}
+
+func TestEnsureIndexes_HasOne(t *testing.T) {
+	setupDefConnection()
+	dropIndexes(t, &IndexedProfile{})
+	defer dropIndexes(t, &IndexedProfile{})
+
+	rel := mgmrel.HasOne(&Doc{}, &IndexedProfile{})
+	missing, err := rel.MissingIndexes(mgm.Ctx())
+	require.NoError(t, err)
+	require.Equal(t, 1, len(missing))
+	assert.True(t, missing[0].Unique)
+	assert.Equal(t, bson.D{{Key: "doc_id", Value: 1}}, missing[0].Keys)
+
+	require.NoError(t, rel.EnsureIndexes(mgm.Ctx()))
+	missing, err = rel.MissingIndexes(mgm.Ctx())
+	require.NoError(t, err)
+	assert.Empty(t, missing)
+
+	// The unique index rejects second profile of a doc.
+	docID := primitive.NewObjectID()
+	require.NoError(t, mgm.Coll(&IndexedProfile{}).Create(&IndexedProfile{DocID: docID}))
+	require.Error(t, mgm.Coll(&IndexedProfile{}).Create(&IndexedProfile{DocID: docID}))
+
+	// Profiles with null foreign key do not conflict.
+	for i := 0; i < 2; i++ {
+		_, err := mgm.Coll(&IndexedProfile{}).InsertOne(mgm.Ctx(), bson.M{"doc_id": nil})
+		require.NoError(t, err)
+	}
+}
+
+func TestEnsureIndexes_HasMany(t *testing.T) {
+	setupDefConnection()
+	dropIndexes(t, &IndexedAuthor{})
+	defer dropIndexes(t, &IndexedAuthor{})
+
+	rel := mgmrel.HasMany(&Doc{}, &IndexedAuthor{})
+	missing, err := rel.MissingIndexes(mgm.Ctx())
+	require.NoError(t, err)
+	require.Equal(t, 1, len(missing))
+	assert.False(t, missing[0].Unique)
+	assert.Equal(t, bson.D{{Key: "doc_id", Value: 1}, {Key: "_id", Value: -1}}, missing[0].Keys)
+
+	require.NoError(t, rel.EnsureIndexes(mgm.Ctx()))
+	missing, err = rel.MissingIndexes(mgm.Ctx())
+	require.NoError(t, err)
+	assert.Empty(t, missing)
+}
+
+func TestEnsureAllIndexes_DryRun(t *testing.T) {
+	setupDefConnection()
+	dropIndexes(t, &IndexedAuthor{})
+	defer dropIndexes(t, &IndexedAuthor{})
+
+	mgmrel.Register("IndexedAuthors", mgmrel.HasMany(&Doc{}, &IndexedAuthor{}))
+
+	missing, err := mgmrel.EnsureAllIndexes(mgm.Ctx(), true)
+	require.NoError(t, err)
+	assert.Contains(t, missing, mgmrel.Index{
+		Collection: "indexed_authors",
+		Keys:       bson.D{{Key: "doc_id", Value: 1}, {Key: "_id", Value: -1}},
+	})
+
+	missing, err = mgmrel.HasMany(&Doc{}, &IndexedAuthor{}).MissingIndexes(mgm.Ctx())
+	require.NoError(t, err)
+	assert.Equal(t, 1, len(missing))
+}
@@ -0,0 +1,295 @@
+package mgmrel
+
+import (
+	"fmt"
+
+	"github.com/kamva/mgm/v3"
+	"github.com/kamva/mgm/v3/builder"
+	f "github.com/kamva/mgm/v3/field"
+	o "github.com/kamva/mgm/v3/operator"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// FixMode is the way that CheckIntegrity fixes the orphaned related models.
+type FixMode string
+
+const (
+	// FixNone just reports the orphaned models.
+	FixNone FixMode = ""
+	// FixDelete deletes the orphaned models.
+	FixDelete FixMode = "delete"
+	// FixNullify sets the foreign key of the orphaned models to null.
+	FixNullify FixMode = "nullify"
+	// FixReassign sets the foreign key of the orphaned models to
+	// the IntegrityOptions's ReassignTo value.
+	FixReassign FixMode = "reassign"
+)
+
+// IntegrityOptions contains the CheckIntegrity options.
+type IntegrityOptions struct {
+	Fix FixMode
+	// ReassignTo is the owner's id that orphaned models are
+	// reassigned to in the FixReassign mode. the owner must exist.
+	ReassignTo interface{}
+	// ReferenceFields are bson name of the owner's array fields that
+	// keep id of the related models. CheckIntegrity reports the ids in
+	// those arrays that point to no related model. all fix modes remove
+	// the dangling ids from the arrays.
+	ReferenceFields []string
+}
+
+// DanglingReference is an id in an owner's reference field
+// that points to no related model.
+type DanglingReference struct {
+	Field   string
+	OwnerID interface{}
+	ID      interface{}
+}
+
+// IntegrityReport is the result of checking a relation's integrity.
+type IntegrityReport struct {
+	Relation RelationInfo
+
+	// Orphans contains id of the related models that their
+	// foreign key points to no owner.
+	Orphans []interface{}
+
+	// DuplicateOwners contains id of the owners that have more than
+	// one related model in a "has one" relation.
+	DuplicateOwners []interface{}
+
+	// DanglingReferences contains the ids in the owners' reference
+	// fields that point to no related model.
+	DanglingReferences []DanglingReference
+
+	// Fixed is the number of the orphaned models that were fixed.
+	Fixed int64
+
+	// FixedReferences is the number of the owners' reference fields
+	// that their dangling ids were removed, counted per owner.
+	FixedReferences int64
+}
+
+// Healthy returns true if the report does not contain any problem.
+func (r *IntegrityReport) Healthy() bool {
+	return len(r.Orphans) == 0 && len(r.DuplicateOwners) == 0 && len(r.DanglingReferences) == 0
+}
+
+// CheckIntegrity checks the relation's referential integrity using aggregation
+// `$lookup` between the related and the owner collections. it reports orphaned
+// related models, "has one" owners that have multiple related models, and the
+// dangling ids in the options's ReferenceFields. you can fix the orphaned models
+// and the dangling ids by the options's Fix mode.
+// the relation can be built using an empty owner model.
+func CheckIntegrity(r Relation, opts ...*IntegrityOptions) (*IntegrityReport, error) {
+	opt := &IntegrityOptions{}
+	if len(opts) != 0 && opts[0] != nil {
+		opt = opts[0]
+	}
+	info := r.Info()
+	switch opt.Fix {
+	case FixNone, FixDelete, FixNullify:
+	case FixReassign:
+		reassignTo, err := prepareReassignTo(info, opt.ReassignTo)
+		if err != nil {
+			return nil, err
+		}
+		prepared := *opt
+		prepared.ReassignTo = reassignTo
+		opt = &prepared
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrInvalidFixMode, opt.Fix)
+	}
+
+	report := &IntegrityReport{Relation: info}
+
+	var err error
+	if report.Orphans, err = findOrphans(info); err != nil {
+		return nil, err
+	}
+	if info.Kind == HasOneKind {
+		if report.DuplicateOwners, err = findDuplicateOwners(info); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, field := range opt.ReferenceFields {
+		dangling, err := findDanglingReferences(info, field)
+		if err != nil {
+			return nil, err
+		}
+		report.DanglingReferences = append(report.DanglingReferences, dangling...)
+	}
+
+	if opt.Fix != FixNone && len(report.Orphans) != 0 {
+		if report.Fixed, err = fixOrphans(r.base(), report.Orphans, opt); err != nil {
+			return report, err
+		}
+	}
+	if opt.Fix != FixNone && len(report.DanglingReferences) != 0 {
+		if report.FixedReferences, err = fixDanglingReferences(info, report.DanglingReferences); err != nil {
+			return report, err
+		}
+	}
+	return report, nil
+}
+
+// prepareReassignTo converts the id to the owner's id type, and checks
+// the owner with that id exists.
+func prepareReassignTo(info RelationInfo, id interface{}) (interface{}, error) {
+	if id == nil {
+		return nil, ErrEmptyReassignTo
+	}
+	id, err := info.Owner.PrepareID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := mgm.Coll(info.Owner).CountDocuments(mgm.Ctx(), bson.M{f.ID: id}, options.Count().SetLimit(1))
+	if err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, fmt.Errorf("%w: %v", ErrReassignToNotFound, id)
+	}
+	return id, nil
+}
+
+type integrityResult struct {
+	ID interface{} `bson:"_id"`
+}
+
+// hasForeignKey returns stage to match related models that have foreign key.
+func hasForeignKey(info RelationInfo) bson.M {
+	return bson.M{o.Match: bson.M{info.ForeignKey: bson.M{o.Exists: true, o.Ne: nil}}}
+}
+
+func findOrphans(info RelationInfo) ([]interface{}, error) {
+	const ownerField = "__owner"
+
+	results := make([]integrityResult, 0)
+	err := mgm.Coll(info.Related).SimpleAggregate(&results,
+		hasForeignKey(info),
+		builder.Lookup(collectionName(info.Owner), info.ForeignKey, f.ID, ownerField),
+		bson.M{o.Match: bson.M{ownerField: bson.M{o.Size: 0}}},
+		bson.M{o.Project: bson.M{f.ID: 1}},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return integrityResultIDs(results), nil
+}
+
+func findDuplicateOwners(info RelationInfo) ([]interface{}, error) {
+	results := make([]integrityResult, 0)
+	err := mgm.Coll(info.Related).SimpleAggregate(&results,
+		hasForeignKey(info),
+		builder.Group("$"+info.ForeignKey, bson.M{"count": bson.M{o.Sum: 1}}),
+		bson.M{o.Match: bson.M{"count": bson.M{o.Gt: 1}}},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return integrityResultIDs(results), nil
+}
+
+// findDanglingReferences finds the ids in the owners' array
+// field that point to no related model.
+func findDanglingReferences(info RelationInfo, field string) ([]DanglingReference, error) {
+	const relatedField = "__related"
+
+	results := make([]struct {
+		OwnerID interface{} `bson:"_id"`
+		ID      interface{} `bson:"ref"`
+	}, 0)
+	err := mgm.Coll(info.Owner).SimpleAggregate(&results,
+		bson.M{o.Match: bson.M{field: bson.M{o.Type: "array", o.Ne: bson.A{}}}},
+		bson.M{o.Unwind: "$" + field},
+		builder.Lookup(collectionName(info.Related), field, f.ID, relatedField),
+		bson.M{o.Match: bson.M{relatedField: bson.M{o.Size: 0}}},
+		bson.M{o.Project: bson.M{f.ID: 1, "ref": "$" + field}},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	dangling := make([]DanglingReference, len(results))
+	for i, res := range results {
+		dangling[i] = DanglingReference{Field: field, OwnerID: res.OwnerID, ID: res.ID}
+	}
+	return dangling, nil
+}
+
+// fixDanglingReferences removes the dangling ids from the owners'
+// reference fields, by a single query per field.
+func fixDanglingReferences(info RelationInfo, dangling []DanglingReference) (int64, error) {
+	fieldIDs := make(map[string][]interface{})
+	var fields []string
+	for _, ref := range dangling {
+		if _, ok := fieldIDs[ref.Field]; !ok {
+			fields = append(fields, ref.Field)
+		}
+		fieldIDs[ref.Field] = append(fieldIDs[ref.Field], ref.ID)
+	}
+
+	var fixed int64
+	for _, field := range fields {
+		ids := fieldIDs[field]
+		res, err := mgm.Coll(info.Owner).UpdateMany(mgm.Ctx(),
+			bson.M{field: bson.M{o.In: ids}},
+			bson.M{o.Pull: bson.M{field: bson.M{o.In: ids}}},
+		)
+		if err != nil {
+			return fixed, err
+		}
+		fixed += res.ModifiedCount
+	}
+	return fixed, nil
+}
+
+// fixOrphans fixes the orphaned models by the fix mode through the
+// relation's middlewares. the delete mode calls to the removal hooks
+// of the models.
+func fixOrphans(r *relation, ids []interface{}, opt *IntegrityOptions) (int64, error) {
+	filter := bson.M{f.ID: bson.M{o.In: ids}}
+
+	switch opt.Fix {
+	case FixDelete:
+		var res *mongo.DeleteResult
+		op := &Op{Operation: OpDelete, Filter: filter}
+		err := r.run(op, func(op *Op) (err error) {
+			res, err = r.deleteByFilter(op.Filter)
+			return
+		})
+		if err != nil {
+			return 0, err
+		}
+		return res.DeletedCount, nil
+	case FixNullify, FixReassign:
+		var owner interface{}
+		if opt.Fix == FixReassign {
+			owner = opt.ReassignTo
+		}
+		var res *mongo.UpdateResult
+		op := &Op{Operation: OpUpdate, Filter: filter, Update: bson.M{o.Set: bson.M{r.foreignKey: owner}}}
+		err := r.run(op, func(op *Op) (err error) {
+			res, err = mgm.Coll(r.related).UpdateMany(mgm.Ctx(), op.Filter, op.Update)
+			return
+		})
+		if err != nil {
+			return 0, err
+		}
+		return res.ModifiedCount, nil
+	}
+	return 0, nil
+}
+
+func integrityResultIDs(results []integrityResult) []interface{} {
+	ids := make([]interface{}, len(results))
+	for i, res := range results {
+		ids[i] = res.ID
+	}
+	return ids
+}
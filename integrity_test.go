@@ -0,0 +1,160 @@
+package mgmrel_test
+
+import (
+	"errors"
+	mgmrel "github.com/kamva/mgm-relation"
+	"github.com/kamva/mgm/v3"
+	f "github.com/kamva/mgm/v3/field"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"testing"
+)
+
+func insertOrphanAuthor(t *testing.T) *DocAuthor {
+	orphan := NewDocAuthor("orphan", primitive.NewObjectID())
+	require.NoError(t, mgm.Coll(orphan).Create(orphan))
+	return orphan
+}
+
+func TestCheckIntegrity_Orphans(t *testing.T) {
+	setupDefConnection()
+	resetCollection()
+	insertHasManyRelation(t)
+	orphan := insertOrphanAuthor(t)
+
+	report, err := mgmrel.CheckIntegrity(mgmrel.HasMany(&Doc{}, &DocAuthor{}))
+	require.NoError(t, err)
+	assert.False(t, report.Healthy())
+	assert.Equal(t, []interface{}{orphan.ID}, report.Orphans)
+	assert.Empty(t, report.DuplicateOwners)
+	assert.Equal(t, int64(0), report.Fixed)
+}
+
+func TestCheckIntegrity_DuplicateOwners(t *testing.T) {
+	setupDefConnection()
+	resetCollection()
+	d, _ := insertHasOneRelation(t)
+	require.NoError(t, mgm.Coll(&DocAuthor{}).Create(NewDocAuthor("extra", d.ID)))
+
+	report, err := mgmrel.CheckIntegrity(mgmrel.HasOne(&Doc{}, &DocAuthor{}))
+	require.NoError(t, err)
+	assert.Empty(t, report.Orphans)
+	assert.Equal(t, []interface{}{d.ID}, report.DuplicateOwners)
+}
+
+func TestCheckIntegrity_FixDelete(t *testing.T) {
+	setupDefConnection()
+	resetCollection()
+	insertHasManyRelation(t)
+	insertOrphanAuthor(t)
+
+	rel := mgmrel.HasMany(&Doc{}, &DocAuthor{})
+	report, err := mgmrel.CheckIntegrity(rel, &mgmrel.IntegrityOptions{Fix: mgmrel.FixDelete})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), report.Fixed)
+
+	report, err = mgmrel.CheckIntegrity(rel)
+	require.NoError(t, err)
+	assert.True(t, report.Healthy())
+}
+
+func TestCheckIntegrity_FixReassign(t *testing.T) {
+	setupDefConnection()
+	resetCollection()
+	d, _ := insertHasManyRelation(t)
+	orphan := insertOrphanAuthor(t)
+
+	rel := mgmrel.HasMany(&Doc{}, &DocAuthor{})
+	_, err := mgmrel.CheckIntegrity(rel, &mgmrel.IntegrityOptions{Fix: mgmrel.FixReassign})
+	require.Equal(t, mgmrel.ErrEmptyReassignTo, err)
+
+	_, err = mgmrel.CheckIntegrity(rel, &mgmrel.IntegrityOptions{Fix: mgmrel.FixReassign, ReassignTo: primitive.NewObjectID()})
+	require.True(t, errors.Is(err, mgmrel.ErrReassignToNotFound))
+
+	// The id is prepared by the owner, so hex ids work too.
+	report, err := mgmrel.CheckIntegrity(rel, &mgmrel.IntegrityOptions{Fix: mgmrel.FixReassign, ReassignTo: d.ID.Hex()})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), report.Fixed)
+
+	found := &DocAuthor{}
+	require.NoError(t, mgm.Coll(found).First(bson.M{f.ID: orphan.ID}, found))
+	assert.Equal(t, d.ID, found.DocID)
+}
+
+func TestCheckIntegrity_FixNullify_Middleware(t *testing.T) {
+	setupDefConnection()
+	resetCollection()
+	insertHasManyRelation(t)
+	orphan := insertOrphanAuthor(t)
+
+	operations := make([]mgmrel.Operation, 0)
+	rel := mgmrel.HasMany(&Doc{}, &DocAuthor{}).Use(func(next mgmrel.SyncFunc) mgmrel.SyncFunc {
+		return func(op *mgmrel.Op) error {
+			operations = append(operations, op.Operation)
+			return next(op)
+		}
+	})
+	report, err := mgmrel.CheckIntegrity(rel, &mgmrel.IntegrityOptions{Fix: mgmrel.FixNullify})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), report.Fixed)
+	assert.Equal(t, []mgmrel.Operation{mgmrel.OpUpdate}, operations)
+
+	found := bson.M{}
+	require.NoError(t, mgm.Coll(orphan).FindOne(mgm.Ctx(), bson.M{f.ID: orphan.ID}).Decode(&found))
+	assert.Nil(t, found["doc_id"])
+}
+
+func TestCheckIntegrity_FixDelete_RemoveHooks(t *testing.T) {
+	setupDefConnection()
+	resetCollection()
+	d, _ := insertRemovableAuthors(t, "B1", "B2")
+	_, err := mgm.Coll(d).DeleteOne(mgm.Ctx(), bson.M{f.ID: d.ID})
+	require.NoError(t, err)
+
+	report, err := mgmrel.CheckIntegrity(mgmrel.HasMany(&Doc{}, &RemovableAuthor{}), &mgmrel.IntegrityOptions{Fix: mgmrel.FixDelete})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), report.Fixed)
+	assert.ElementsMatch(t, []string{"B1", "B2"}, removedAuthors)
+}
+
+func TestCheckIntegrity_InvalidFixMode(t *testing.T) {
+	_, err := mgmrel.CheckIntegrity(mgmrel.HasMany(&Doc{}, &DocAuthor{}), &mgmrel.IntegrityOptions{Fix: "unknown"})
+	assert.True(t, errors.Is(err, mgmrel.ErrInvalidFixMode))
+}
+
+type DocWithAuthorIDs struct {
+	mgmrel.IDField `bson:",inline"`
+
+	AuthorIDs []primitive.ObjectID `bson:"author_ids"`
+}
+
+func (d *DocWithAuthorIDs) CollectionName() string {
+	return "docs"
+}
+
+func TestCheckIntegrity_DanglingReferences(t *testing.T) {
+	setupDefConnection()
+	resetCollection()
+	_, authors := insertHasManyRelation(t)
+	missing := primitive.NewObjectID()
+	d := &DocWithAuthorIDs{AuthorIDs: []primitive.ObjectID{authors[0].ID, missing}}
+	require.NoError(t, mgm.Coll(d).Create(d))
+
+	rel := mgmrel.HasMany(&DocWithAuthorIDs{}, &DocAuthor{})
+	opts := &mgmrel.IntegrityOptions{ReferenceFields: []string{"author_ids"}}
+	report, err := mgmrel.CheckIntegrity(rel, opts)
+	require.NoError(t, err)
+	assert.False(t, report.Healthy())
+	assert.Equal(t, []mgmrel.DanglingReference{{Field: "author_ids", OwnerID: d.ID, ID: missing}}, report.DanglingReferences)
+
+	opts.Fix = mgmrel.FixDelete
+	report, err = mgmrel.CheckIntegrity(rel, opts)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), report.FixedReferences)
+
+	found := &DocWithAuthorIDs{}
+	require.NoError(t, mgm.Coll(found).FindByID(d.ID, found))
+	assert.Equal(t, []primitive.ObjectID{authors[0].ID}, found.AuthorIDs)
+}
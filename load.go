@@ -0,0 +1,322 @@
+package mgmrel
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/kamva/mgm/v3"
+	"github.com/kamva/mgm/v3/builder"
+	f "github.com/kamva/mgm/v3/field"
+	o "github.com/kamva/mgm/v3/operator"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// LoadQuery is the query that loads a relation path's models.
+// constraints can change it.
+type LoadQuery struct {
+	// Filter is the extra filter of the related models.
+	Filter bson.M
+	// Sort is the sort field, default is the relation's default sort.
+	// you can sort descending by adding a `-` to the sort field. e.g `-created_at`
+	Sort string
+	// Limit limits number of the related models per owner,
+	// default is the relation's eager limit. zero means no limit.
+	Limit int64
+}
+
+// Constraint changes the query of loading a relation path.
+type Constraint func(q *LoadQuery)
+
+// loadNode is a node of the relation paths tree.
+type loadNode struct {
+	path       string
+	constraint Constraint
+	children   []*loadNode
+}
+
+func (n *loadNode) child(name string) *loadNode {
+	for _, c := range n.children {
+		if c.path[strings.LastIndex(c.path, ".")+1:] == name {
+			return c
+		}
+	}
+
+	path := name
+	if n.path != "" {
+		path = n.path + "." + name
+	}
+	c := &loadNode{path: path}
+	n.children = append(n.children, c)
+	return c
+}
+
+func (n *loadNode) name() string {
+	return n.path[strings.LastIndex(n.path, ".")+1:]
+}
+
+// Load eager loads the registered relations of the owners into the owners
+// fields with the same names. nested relations are separated by dot.
+// e.g: mgmrel.Load(docs, "Authors.Addresses", "Profile")
+// owners can be a model, slice of models or pointer to slice of models.
+// it runs a single `$in` query per relation path, not per owner.
+// it applies the relations' scope and projection, like FindWith.
+func Load(owners interface{}, paths ...string) error {
+	constraints := make(map[string]Constraint, len(paths))
+	for _, p := range paths {
+		constraints[p] = nil
+	}
+	return LoadWithConstraints(owners, constraints)
+}
+
+// LoadWithConstraints eager loads the relation paths (the map keys) and
+// applies their constraints (the map values) on loading each path.
+// constraints can be nil.
+func LoadWithConstraints(owners interface{}, constraints map[string]Constraint) error {
+	root := &loadNode{}
+	for p, constraint := range constraints {
+		n := root
+		for _, name := range strings.Split(p, ".") {
+			n = n.child(name)
+		}
+		n.constraint = constraint
+	}
+
+	return loadChildren(toModelList(owners), root)
+}
+
+func loadChildren(owners []mgm.Model, n *loadNode) error {
+	if len(owners) == 0 {
+		return nil
+	}
+
+	for _, c := range n.children {
+		related, err := loadRelation(owners, c)
+		if err != nil {
+			return err
+		}
+		if err := loadChildren(related, c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadRelation loads the node's relation models of all owners by a
+// single query, sets them on the owners and returns the loaded models
+// the way that the owners keep them.
+func loadRelation(owners []mgm.Model, n *loadNode) ([]mgm.Model, error) {
+	rel, err := RegisteredRelation(owners[0], n.name())
+	if err != nil {
+		return nil, err
+	}
+	r := rel.base()
+
+	q := &LoadQuery{Filter: bson.M{}, Sort: r.sort, Limit: r.eagerLimit}
+	if n.constraint != nil {
+		n.constraint(q)
+	}
+
+	cur, err := r.loadCursor(extractIDs(owners), q)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(mgm.Ctx())
+
+	// Group the related models by their owner's id.
+	grouped := make(map[string][]mgm.Model)
+	for cur.Next(mgm.Ctx()) {
+		// The key has the same format as the idKey's keys.
+		fk := cur.Current.Lookup(r.foreignKey)
+		key := string(fk.Type) + string(fk.Value)
+
+		m := r.newRelatedModel()
+		if err := cur.Decode(m); err != nil {
+			return nil, err
+		}
+		grouped[key] = append(grouped[key], m)
+	}
+	if err := cur.Err(); err != nil {
+		return nil, err
+	}
+
+	var loaded []mgm.Model
+	for _, owner := range owners {
+		key, err := idKey(owner.GetID())
+		if err != nil {
+			return nil, err
+		}
+		field, err := relationField(owner, n.name())
+		if err != nil {
+			return nil, err
+		}
+		loaded = append(loaded, setRelationModels(field, grouped[key])...)
+	}
+	return loaded, nil
+}
+
+// loadCursor finds the related models of the owners. if the query has
+// limit, it aggregates the owners and limits the models of each owner
+// in the `$lookup` stage.
+// it applies the relation's projection, but always keeps the foreign key.
+func (r *relation) loadCursor(ownerIDs []interface{}, q *LoadQuery) (*mongo.Cursor, error) {
+	filter := bson.M{r.foreignKey: bson.M{o.In: ownerIDs}}
+	for k, v := range r.activeScope() {
+		filter[k] = v
+	}
+	for k, v := range q.Filter {
+		filter[k] = v
+	}
+
+	var projection bson.M
+	if r.projection != nil {
+		var err error
+		if projection, err = projectionWith(r.projection, r.foreignKey); err != nil {
+			return nil, err
+		}
+	}
+
+	if q.Limit <= 0 {
+		opts := options.Find()
+		if q.Sort != "" {
+			opts.SetSort(sortFieldToBsonD(q.Sort))
+		}
+		if projection != nil {
+			opts.SetProjection(projection)
+		}
+		return mgm.Coll(r.related).Find(mgm.Ctx(), filter, opts)
+	}
+
+	// The limit applies per owner, so we look up the related models of
+	// each owner with the limit, rather than grouping all of them.
+	pipeline := bson.A{
+		bson.M{o.Match: filter},
+		bson.M{o.Match: bson.M{o.Expr: bson.M{o.Eq: bson.A{"$" + r.foreignKey, "$$owner_id"}}}},
+	}
+	if q.Sort != "" {
+		pipeline = append(pipeline, bson.M{o.Sort: sortFieldToBsonD(q.Sort)})
+	}
+	pipeline = append(pipeline, bson.M{o.Limit: q.Limit})
+	if projection != nil {
+		pipeline = append(pipeline, bson.M{o.Project: projection})
+	}
+
+	const docsField = "docs"
+	let := bson.M{"owner_id": "$" + f.ID}
+	return mgm.Coll(r.m).Aggregate(mgm.Ctx(), bson.A{
+		bson.M{o.Match: bson.M{f.ID: bson.M{o.In: ownerIDs}}},
+		builder.S(builder.UncorrelatedLookup(collectionName(r.related), let, pipeline, docsField)),
+		bson.M{o.Unwind: "$" + docsField},
+		bson.M{o.ReplaceRoot: bson.M{"newRoot": "$" + docsField}},
+	})
+}
+
+// projectionWith returns the projection that keeps the field too.
+func projectionWith(projection interface{}, field string) (bson.M, error) {
+	b, err := bson.Marshal(projection)
+	if err != nil {
+		return nil, err
+	}
+	result := bson.M{}
+	if err := bson.Unmarshal(b, &result); err != nil {
+		return nil, err
+	}
+
+	if v, ok := result[field]; ok && isExcluded(v) {
+		delete(result, field)
+		return result, nil
+	}
+	for k, v := range result {
+		if k != f.ID && !isExcluded(v) {
+			// It's an inclusion projection.
+			result[field] = 1
+			break
+		}
+	}
+	return result, nil
+}
+
+// isExcluded checks whether the projection value excludes the field.
+func isExcluded(v interface{}) bool {
+	switch v := v.(type) {
+	case bool:
+		return !v
+	case int32:
+		return v == 0
+	case int64:
+		return v == 0
+	case float64:
+		return v == 0
+	default:
+		return false
+	}
+}
+
+// setRelationModels sets the related models on the owner's relation field.
+// slice fields get all of the models, other fields get the first one.
+// it returns the models that the field keeps, so loading the nested
+// relations changes the owner's models, even if the field keeps values.
+func setRelationModels(field reflect.Value, models []mgm.Model) []mgm.Model {
+	if field.Kind() != reflect.Slice {
+		if len(models) == 0 {
+			field.Set(reflect.Zero(field.Type()))
+			return nil
+		}
+		field.Set(modelValue(models[0], field.Type()))
+		return []mgm.Model{fieldModel(field)}
+	}
+
+	slice := reflect.MakeSlice(field.Type(), 0, len(models))
+	for _, m := range models {
+		slice = reflect.Append(slice, modelValue(m, field.Type().Elem()))
+	}
+	field.Set(slice)
+
+	kept := make([]mgm.Model, field.Len())
+	for i := range kept {
+		kept[i] = fieldModel(field.Index(i))
+	}
+	return kept
+}
+
+// modelValue returns the model as value of the provided type, which
+// can be pointer to the model's type, or the model's type itself.
+func modelValue(m mgm.Model, t reflect.Type) reflect.Value {
+	v := reflect.ValueOf(m)
+	if t.Kind() != reflect.Ptr {
+		return v.Elem()
+	}
+	return v
+}
+
+// fieldModel returns the model that the addressable value keeps.
+func fieldModel(v reflect.Value) mgm.Model {
+	if v.Kind() != reflect.Ptr {
+		v = v.Addr()
+	}
+	return v.Interface().(mgm.Model)
+}
+
+// toModelList converts a model, slice of models or pointer
+// to slice of models to the list of models.
+func toModelList(owners interface{}) []mgm.Model {
+	if m, ok := owners.(mgm.Model); ok {
+		return []mgm.Model{m}
+	}
+
+	v := reflect.ValueOf(owners)
+	for v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+
+	models := make([]mgm.Model, 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		elem := v.Index(i)
+		if elem.Kind() != reflect.Ptr {
+			elem = elem.Addr()
+		}
+		models = append(models, elem.Interface().(mgm.Model))
+	}
+	return models
+}
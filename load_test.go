@@ -0,0 +1,144 @@
+package mgmrel_test
+
+import (
+	"errors"
+	"github.com/kamva/gutil"
+	mgmrel "github.com/kamva/mgm-relation"
+	"github.com/kamva/mgm/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"testing"
+)
+
+type LoadDoc struct {
+	mgmrel.IDField `bson:",inline"`
+
+	Name string `bson:"name"`
+
+	Authors []*LoadAuthor `bson:"-"`
+	Profile LoadAuthor    `bson:"-"`
+}
+
+func (d *LoadDoc) CollectionName() string {
+	return "docs"
+}
+
+type LoadAuthor struct {
+	mgmrel.IDField `bson:",inline"`
+
+	Name  string             `bson:"name"`
+	DocID primitive.ObjectID `bson:"doc_id"`
+
+	Notes []*AuthorNote `bson:"-"`
+}
+
+func (a *LoadAuthor) CollectionName() string {
+	return "doc_authors"
+}
+
+type LoadDocWithValues struct {
+	mgmrel.IDField `bson:",inline"`
+
+	Authors []LoadAuthor `bson:"-"`
+}
+
+func (d *LoadDocWithValues) CollectionName() string {
+	return "docs"
+}
+
+type AuthorNote struct {
+	mgmrel.IDField `bson:",inline"`
+
+	Text     string             `bson:"text"`
+	AuthorID primitive.ObjectID `bson:"author_id"`
+}
+
+func init() {
+	mgmrel.Register("Authors", mgmrel.HasManyWithOptions(&LoadDoc{}, &LoadAuthor{}, "doc_id").DefaultSort("name"))
+	mgmrel.Register("Profile", mgmrel.HasOneByOptions(&LoadDoc{}, &LoadAuthor{}, "doc_id").DefaultSort("-name"))
+	mgmrel.Register("Authors", mgmrel.HasManyWithOptions(&LoadDocWithValues{}, &LoadAuthor{}, "doc_id").DefaultSort("name"))
+	mgmrel.Register("Notes", mgmrel.HasManyWithOptions(&LoadAuthor{}, &AuthorNote{}, "author_id").DefaultSort("text"))
+}
+
+func insertAuthorNotes(t *testing.T, authors []*DocAuthor) {
+	_, err := mgm.Coll(&AuthorNote{}).DeleteMany(mgm.Ctx(), bson.M{})
+	gutil.PanicErr(err)
+
+	for _, a := range authors {
+		notes := []*AuthorNote{
+			{Text: a.Name + "-n1", AuthorID: a.ID},
+			{Text: a.Name + "-n2", AuthorID: a.ID},
+		}
+		require.NoError(t, mgmrel.HasManyWithOptions(a, &AuthorNote{}, "author_id").Sync(notes))
+	}
+}
+
+func TestLoad_Nested(t *testing.T) {
+	setupDefConnection()
+	resetCollection()
+	d, authors := insertHasManyRelation(t)
+	insertAuthorNotes(t, authors)
+
+	docs := []*LoadDoc{{IDField: d.IDField}}
+	require.NoError(t, mgmrel.Load(docs, "Authors.Notes", "Profile"))
+
+	require.Equal(t, len(authors), len(docs[0].Authors))
+	for i, author := range authors {
+		assert.Equal(t, author.ID, docs[0].Authors[i].ID)
+		require.Equal(t, 2, len(docs[0].Authors[i].Notes))
+		assert.Equal(t, author.Name+"-n1", docs[0].Authors[i].Notes[0].Text)
+		assert.Equal(t, author.Name+"-n2", docs[0].Authors[i].Notes[1].Text)
+	}
+	assert.Equal(t, authors[1].ID, docs[0].Profile.ID)
+}
+
+func TestLoad_NestedValues(t *testing.T) {
+	setupDefConnection()
+	resetCollection()
+	d, authors := insertHasManyRelation(t)
+	insertAuthorNotes(t, authors)
+
+	docs := []LoadDocWithValues{{IDField: d.IDField}}
+	require.NoError(t, mgmrel.Load(&docs, "Authors.Notes"))
+	require.Equal(t, len(authors), len(docs[0].Authors))
+	for i, author := range authors {
+		require.Equal(t, 2, len(docs[0].Authors[i].Notes))
+		assert.Equal(t, author.Name+"-n1", docs[0].Authors[i].Notes[0].Text)
+	}
+
+	loadDocs := []*LoadDoc{{IDField: d.IDField}}
+	require.NoError(t, mgmrel.Load(loadDocs, "Profile.Notes"))
+	assert.Equal(t, authors[1].ID, loadDocs[0].Profile.ID)
+	require.Equal(t, 2, len(loadDocs[0].Profile.Notes))
+	assert.Equal(t, authors[1].Name+"-n1", loadDocs[0].Profile.Notes[0].Text)
+}
+
+func TestLoadWithConstraints(t *testing.T) {
+	setupDefConnection()
+	resetCollection()
+	d, authors := insertHasManyRelation(t)
+	insertAuthorNotes(t, authors)
+
+	docs := []LoadDoc{{IDField: d.IDField}}
+	require.NoError(t, mgmrel.LoadWithConstraints(&docs, map[string]mgmrel.Constraint{
+		"Authors": func(q *mgmrel.LoadQuery) {
+			q.Filter["name"] = authors[0].Name
+		},
+		"Authors.Notes": func(q *mgmrel.LoadQuery) {
+			q.Sort = "-text"
+			q.Limit = 1
+		},
+	}))
+
+	require.Equal(t, 1, len(docs[0].Authors))
+	assert.Equal(t, authors[0].ID, docs[0].Authors[0].ID)
+	require.Equal(t, 1, len(docs[0].Authors[0].Notes))
+	assert.Equal(t, authors[0].Name+"-n2", docs[0].Authors[0].Notes[0].Text)
+}
+
+func TestLoad_UnknownRelation(t *testing.T) {
+	docs := []*LoadDoc{{}}
+	assert.True(t, errors.Is(mgmrel.Load(docs, "Unknown"), mgmrel.ErrRelationNotFound))
+}
@@ -0,0 +1,105 @@
+package mgmrel
+
+import (
+	"github.com/kamva/mgm/v3"
+)
+
+// Operation is name of the relation's operation.
+type Operation string
+
+const (
+	// OpGet is the get operation (Get, SimpleGet, GetWithOptions,...).
+	OpGet Operation = "get"
+	// OpSync is the sync operation.
+	OpSync Operation = "sync"
+	// OpSyncWithoutRemove is the sync operation that does not remove other models.
+	OpSyncWithoutRemove Operation = "sync_without_remove"
+	// OpDelete is the operation that removes the related models.
+	OpDelete Operation = "delete"
+	// OpCreate is the operation that inserts new related models.
+	OpCreate Operation = "create"
+	// OpUpdate is the operation that updates the related models.
+	OpUpdate Operation = "update"
+	// OpReorder is the operation that rewrites positions of the related models.
+	OpReorder Operation = "reorder"
+)
+
+// RelationKind is kind of the relation.
+type RelationKind string
+
+const (
+	// HasOneKind is kind of the "has one" relations.
+	HasOneKind RelationKind = "has_one"
+	// HasManyKind is kind of the "has many" relations.
+	HasManyKind RelationKind = "has_many"
+)
+
+// RelationInfo contains the relation's metadata.
+type RelationInfo struct {
+	Kind       RelationKind
+	Owner      mgm.Model
+	Related    mgm.Model
+	ForeignKey string
+}
+
+// Op contains the operation that a middleware wraps.
+type Op struct {
+	Operation Operation
+	Relation  RelationInfo
+
+	// Models is list of the models that the sync and create operations write.
+	// middlewares can change this list.
+	Models []mgm.Model
+
+	// Filter is the filter of the get, update and delete operations.
+	Filter interface{}
+
+	// Update is the update document of the update operations.
+	Update interface{}
+
+	// Results is the results param of the get operations.
+	Results interface{}
+}
+
+// SyncFunc runs a relation's operation.
+type SyncFunc func(op *Op) error
+
+// Middleware wraps the relation's operations. e.g to audit
+// operations, collect metrics or check permissions.
+type Middleware func(next SyncFunc) SyncFunc
+
+var middlewares []Middleware
+
+// Use adds global middlewares that wrap the operations of all relations.
+// global middlewares wrap the relation's own middlewares.
+func Use(mw ...Middleware) {
+	middlewares = append(middlewares, mw...)
+}
+
+// ResetMiddlewares removes all of the global middlewares.
+func ResetMiddlewares() {
+	middlewares = nil
+}
+
+// info returns the relation's metadata.
+func (r *relation) info() RelationInfo {
+	return RelationInfo{
+		Kind:       r.kind,
+		Owner:      r.m,
+		Related:    r.related,
+		ForeignKey: r.foreignKey,
+	}
+}
+
+// run runs the operation through the global and the relation's middlewares.
+func (r *relation) run(op *Op, fn SyncFunc) error {
+	op.Relation = r.info()
+
+	for i := len(r.middlewares) - 1; i >= 0; i-- {
+		fn = r.middlewares[i](fn)
+	}
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		fn = middlewares[i](fn)
+	}
+	return fn(op)
+}
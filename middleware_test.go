@@ -0,0 +1,87 @@
+package mgmrel_test
+
+import (
+	"errors"
+	mgmrel "github.com/kamva/mgm-relation"
+	"github.com/kamva/mgm/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"testing"
+)
+
+var errStopped = errors.New("stopped by middleware")
+
+func stopMiddleware(ops *[]*mgmrel.Op) mgmrel.Middleware {
+	return func(next mgmrel.SyncFunc) mgmrel.SyncFunc {
+		return func(op *mgmrel.Op) error {
+			*ops = append(*ops, op)
+			return errStopped
+		}
+	}
+}
+
+func TestMiddleware_Order(t *testing.T) {
+	defer mgmrel.ResetMiddlewares()
+	calls := make([]string, 0)
+	named := func(name string) mgmrel.Middleware {
+		return func(next mgmrel.SyncFunc) mgmrel.SyncFunc {
+			return func(op *mgmrel.Op) error {
+				calls = append(calls, name)
+				return next(op)
+			}
+		}
+	}
+
+	var ops []*mgmrel.Op
+	mgmrel.Use(named("global1"), named("global2"))
+	rel := mgmrel.HasMany(NewDoc("A", 12), &DocAuthor{}).Use(named("relation"), stopMiddleware(&ops))
+
+	assert.Equal(t, errStopped, rel.SimpleGet(&[]*DocAuthor{}, 10))
+	assert.Equal(t, []string{"global1", "global2", "relation"}, calls)
+}
+
+func TestMiddleware_Operations(t *testing.T) {
+	d := NewDoc("A", 12)
+	d.ID = primitive.NewObjectID()
+	authors := []*DocAuthor{NewDocAuthor("B1", d.ID)}
+
+	var ops []*mgmrel.Op
+	hasMany := mgmrel.HasMany(d, &DocAuthor{}).Use(stopMiddleware(&ops))
+	hasOne := mgmrel.HasOne(d, &DocAuthor{}).Use(stopMiddleware(&ops))
+
+	assert.Equal(t, errStopped, hasMany.Get(&[]*DocAuthor{}, "", 0, 1))
+	assert.Equal(t, errStopped, hasMany.Sync(authors))
+	assert.Equal(t, errStopped, hasMany.SyncWithoutRemove(authors))
+	assert.Equal(t, errStopped, hasOne.Get(&DocAuthor{}))
+	assert.Equal(t, errStopped, hasOne.Sync(authors[0]))
+
+	require.Equal(t, 5, len(ops))
+	assert.Equal(t, mgmrel.OpGet, ops[0].Operation)
+	assert.Equal(t, mgmrel.OpSync, ops[1].Operation)
+	assert.Equal(t, mgmrel.OpSyncWithoutRemove, ops[2].Operation)
+	assert.Equal(t, mgmrel.OpGet, ops[3].Operation)
+	assert.Equal(t, mgmrel.OpSync, ops[4].Operation)
+
+	assert.Equal(t, []mgm.Model{authors[0]}, ops[1].Models)
+	assert.Equal(t, mgmrel.HasManyKind, ops[1].Relation.Kind)
+	assert.Equal(t, "doc_id", ops[1].Relation.ForeignKey)
+	assert.Equal(t, d, ops[1].Relation.Owner)
+	assert.Equal(t, mgmrel.HasOneKind, ops[4].Relation.Kind)
+}
+
+func TestMiddleware_Delete(t *testing.T) {
+	setupDefConnection()
+	resetCollection()
+	d, _ := insertHasManyRelation(t)
+
+	operations := make([]mgmrel.Operation, 0)
+	rel := mgmrel.HasMany(d, &DocAuthor{}).Use(func(next mgmrel.SyncFunc) mgmrel.SyncFunc {
+		return func(op *mgmrel.Op) error {
+			operations = append(operations, op.Operation)
+			return next(op)
+		}
+	})
+	require.NoError(t, rel.Sync(nil))
+	assert.Equal(t, []mgmrel.Operation{mgmrel.OpSync, mgmrel.OpDelete}, operations)
+}
@@ -0,0 +1,76 @@
+package mgmrel
+
+import (
+	"strings"
+
+	"github.com/jinzhu/inflection"
+	"github.com/kamva/gutil"
+	"github.com/kamva/mgm/v3"
+)
+
+// NamingStrategy generates the foreign key field name of the
+// related models from the owner model.
+type NamingStrategy interface {
+	// ForeignKey returns foreign key field name of the model.
+	ForeignKey(m mgm.Model) string
+}
+
+// NamingStrategyFunc is a function that implements the NamingStrategy.
+type NamingStrategyFunc func(m mgm.Model) string
+
+// ForeignKey calls to the naming function.
+func (fn NamingStrategyFunc) ForeignKey(m mgm.Model) string {
+	return fn(m)
+}
+
+// SnakeCaseNaming generates snake_case foreign keys from the model's
+// type name. e.g get the "DocAuthor" model and returns "doc_author_id".
+type SnakeCaseNaming struct{}
+
+// ForeignKey returns snake_case foreign key name.
+func (SnakeCaseNaming) ForeignKey(m mgm.Model) string {
+	return gutil.ToSnakeCase(modelTypeName(m)) + "_id"
+}
+
+// CamelCaseNaming generates camelCase foreign keys from the model's
+// type name. e.g get the "DocAuthor" model and returns "docAuthorId".
+type CamelCaseNaming struct{}
+
+// ForeignKey returns camelCase foreign key name.
+func (CamelCaseNaming) ForeignKey(m mgm.Model) string {
+	return snakeToCamel(gutil.ToSnakeCase(modelTypeName(m)) + "_id")
+}
+
+// CollectionNaming generates snake_case foreign keys from the singular
+// form of the model's collection name. e.g get a model that its
+// collection is "documents" and returns "document_id".
+type CollectionNaming struct{}
+
+// ForeignKey returns collection name based foreign key name.
+func (CollectionNaming) ForeignKey(m mgm.Model) string {
+	return inflection.Singular(collectionName(m)) + "_id"
+}
+
+var defaultNamingStrategy NamingStrategy = SnakeCaseNaming{}
+
+// SetNamingStrategy sets the global naming strategy that
+// relations use to generate their foreign key.
+func SetNamingStrategy(s NamingStrategy) {
+	defaultNamingStrategy = s
+}
+
+// GetNamingStrategy returns the global naming strategy.
+func GetNamingStrategy() NamingStrategy {
+	return defaultNamingStrategy
+}
+
+// snakeToCamel converts snake_case string to the camelCase.
+func snakeToCamel(s string) string {
+	parts := strings.Split(s, "_")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] != "" {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
@@ -0,0 +1,54 @@
+package mgmrel_test
+
+import (
+	mgmrel "github.com/kamva/mgm-relation"
+	"github.com/kamva/mgm/v3"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type ValueDoc struct{}
+
+func (ValueDoc) PrepareID(id interface{}) (interface{}, error) { return id, nil }
+func (ValueDoc) GetID() interface{}                            { return nil }
+func (ValueDoc) SetID(id interface{})                          {}
+
+type NamedCollDoc struct {
+	mgmrel.IDField `bson:",inline"`
+}
+
+func (d *NamedCollDoc) CollectionName() string {
+	return "documents"
+}
+
+func TestSnakeCaseNaming_ForeignKey(t *testing.T) {
+	assert.Equal(t, "doc_id", mgmrel.SnakeCaseNaming{}.ForeignKey(&Doc{}))
+	assert.Equal(t, "doc_author_id", mgmrel.SnakeCaseNaming{}.ForeignKey(&DocAuthor{}))
+	assert.Equal(t, "value_doc_id", mgmrel.SnakeCaseNaming{}.ForeignKey(ValueDoc{}))
+}
+
+func TestCamelCaseNaming_ForeignKey(t *testing.T) {
+	assert.Equal(t, "docId", mgmrel.CamelCaseNaming{}.ForeignKey(&Doc{}))
+	assert.Equal(t, "docAuthorId", mgmrel.CamelCaseNaming{}.ForeignKey(&DocAuthor{}))
+	assert.Equal(t, "valueDocId", mgmrel.CamelCaseNaming{}.ForeignKey(ValueDoc{}))
+}
+
+func TestCollectionNaming_ForeignKey(t *testing.T) {
+	assert.Equal(t, "doc_id", mgmrel.CollectionNaming{}.ForeignKey(&Doc{}))
+	assert.Equal(t, "document_id", mgmrel.CollectionNaming{}.ForeignKey(&NamedCollDoc{}))
+	assert.Equal(t, "value_doc_id", mgmrel.CollectionNaming{}.ForeignKey(ValueDoc{}))
+}
+
+func TestNamingStrategyFunc_ForeignKey(t *testing.T) {
+	s := mgmrel.NamingStrategyFunc(func(m mgm.Model) string {
+		return "owner"
+	})
+	assert.Equal(t, "owner", s.ForeignKey(&Doc{}))
+}
+
+func TestSetNamingStrategy(t *testing.T) {
+	defer mgmrel.SetNamingStrategy(mgmrel.GetNamingStrategy())
+
+	mgmrel.SetNamingStrategy(mgmrel.CamelCaseNaming{})
+	assert.Equal(t, mgmrel.CamelCaseNaming{}, mgmrel.GetNamingStrategy())
+}
@@ -0,0 +1,62 @@
+package mgmrel
+
+import (
+	"context"
+
+	"github.com/kamva/mgm/v3"
+	f "github.com/kamva/mgm/v3/field"
+	o "github.com/kamva/mgm/v3/operator"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// checkOwnership checks none of the models belong to another owner.
+// otherwise returns the ForeignOwnershipError. relations that allow
+// reparent skip this check.
+func (r *relation) checkOwnership(models []mgm.Model) error {
+	return r.checkOwnershipByIDs(extractIDs(models))
+}
+
+// checkOwnershipByIDs checks none of the models with provided
+// ids belong to another owner.
+func (r *relation) checkOwnershipByIDs(ids []interface{}) error {
+	if r.allowReparent || len(ids) == 0 {
+		return nil
+	}
+
+	filter := bson.M{
+		f.ID:         bson.M{o.In: ids},
+		r.foreignKey: bson.M{o.Nin: bson.A{r.m.GetID(), nil}},
+	}
+	foreignIDs, err := r.findIDs(mgm.Ctx(), filter)
+	if err != nil {
+		return err
+	}
+
+	if len(foreignIDs) != 0 {
+		return &ForeignOwnershipError{IDs: foreignIDs}
+	}
+	return nil
+}
+
+// findIDs returns id of the related models that match the filter.
+func (r *relation) findIDs(ctx context.Context, filter interface{}, opts ...*options.FindOptions) ([]interface{}, error) {
+	opts = append([]*options.FindOptions{options.Find().SetProjection(bson.M{f.ID: 1})}, opts...)
+	cur, err := mgm.Coll(r.related).Find(ctx, filter, opts...)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	var ids []interface{}
+	for cur.Next(ctx) {
+		doc := struct {
+			ID interface{} `bson:"_id"`
+		}{}
+		if err := cur.Decode(&doc); err != nil {
+			return nil, err
+		}
+		ids = append(ids, doc.ID)
+	}
+	return ids, cur.Err()
+}
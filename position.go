@@ -0,0 +1,371 @@
+package mgmrel
+
+import (
+	"context"
+	"errors"
+
+	"github.com/kamva/mgm/v3"
+	f "github.com/kamva/mgm/v3/field"
+	o "github.com/kamva/mgm/v3/operator"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// PositionFieldName is bson name of the position field
+// of the ordered relations' models.
+const PositionFieldName = "position"
+
+// Positioned is the interface to implement by related models of the
+// ordered relations to get their position on sync. the relation writes
+// the position field even if the model does not implement it. you can
+// embed the PositionField in your model to implement it.
+type Positioned interface {
+	GetPosition() int64
+	SetPosition(p int64)
+}
+
+// PositionField struct contain model's position field.
+type PositionField struct {
+	Position int64 `json:"position" bson:"position"`
+}
+
+// GetPosition returns the model's position.
+func (f *PositionField) GetPosition() int64 {
+	return f.Position
+}
+
+// SetPosition sets the model's position.
+func (f *PositionField) SetPosition(p int64) {
+	f.Position = p
+}
+
+var _ Positioned = &PositionField{}
+
+// Ordered sets whether the relation keeps order of the related models.
+// ordered relations write the position field of the models by their
+// index in the synced list, and sort the models by their position.
+// SyncWithoutRemove keeps position of the existing models and places
+// the new models after the other related models. it keeps the sort
+// that is set by DefaultSort.
+func (r *HasManyRelation) Ordered(enable bool) *HasManyRelation {
+	r.ordered = enable
+	if r.sort != defaultHasManySort && r.sort != PositionFieldName {
+		return r
+	}
+	if enable {
+		r.sort = PositionFieldName
+	} else {
+		r.sort = defaultHasManySort
+	}
+	return r
+}
+
+// MoveTo moves the related model with provided id to the index
+// and shifts the other models. it rewrites the positions by a
+// single bulk write, see Reorder.
+func (r *HasManyRelation) MoveTo(id interface{}, index int) error {
+	return r.reorder([]interface{}{id}, func(ids []interface{}, indexes []int) []interface{} {
+		from := indexes[0]
+		moved := ids[from]
+		ids = append(ids[:from], ids[from+1:]...)
+
+		if index < 0 {
+			index = 0
+		}
+		if index > len(ids) {
+			index = len(ids)
+		}
+		ids = append(ids[:index], append([]interface{}{moved}, ids[index:]...)...)
+		return ids
+	})
+}
+
+// Swap swaps positions of the related models with provided ids.
+func (r *HasManyRelation) Swap(a, b interface{}) error {
+	return r.reorder([]interface{}{a, b}, func(ids []interface{}, indexes []int) []interface{} {
+		ids[indexes[0]], ids[indexes[1]] = ids[indexes[1]], ids[indexes[0]]
+		return ids
+	})
+}
+
+// Reorder places the related models with provided ids at the start in
+// the same order, and the other related models after them in their
+// current order. it rewrites the positions by a single bulk write.
+//
+// Reorder reads the current order and writes the new positions in a
+// transaction. standalone servers do not support transactions, so on
+// them it falls back to run without a transaction: if the bulk write
+// fails in the middle, some of the positions are rewritten and others
+// are not, and a concurrent write between the read and the write can
+// be lost. if the owner is Versioned, concurrent reorders of the same
+// owner's models conflict and one of them returns the
+// ConcurrentModificationError.
+func (r *HasManyRelation) Reorder(ids ...interface{}) error {
+	return r.reorder(ids, func(current []interface{}, indexes []int) []interface{} {
+		placed := make(map[int]bool, len(indexes))
+		result := make([]interface{}, 0, len(current))
+		for _, i := range indexes {
+			if !placed[i] {
+				placed[i] = true
+				result = append(result, current[i])
+			}
+		}
+		for i, id := range current {
+			if !placed[i] {
+				result = append(result, id)
+			}
+		}
+		return result
+	})
+}
+
+// reorder finds index of the ids in the current order of the related
+// models, and writes positions of the order that fn returns. it returns
+// the ModelsNotFoundError if some of the ids are not related to the owner.
+func (r *HasManyRelation) reorder(ids []interface{}, fn func(current []interface{}, indexes []int) []interface{}) error {
+	if !r.ordered {
+		return ErrNotOrdered
+	}
+	ids, err := r.prepareIDs(ids)
+	if err != nil {
+		return err
+	}
+
+	op := &Op{Operation: OpReorder, Filter: r.filterByRelation(nil)}
+	return r.run(op, func(op *Op) error {
+		versioned, isVersioned := r.m.(Versioned)
+		var version int64
+		if isVersioned {
+			version = versioned.GetVersion()
+		}
+
+		var transactional bool
+		err := inTransaction(func(ctx context.Context) error {
+			_, transactional = ctx.(mongo.SessionContext)
+
+			// We lock the owner before reading the current order, so a
+			// concurrent reorder can not change it before we rewrite it.
+			if err := r.lockOwnerWithCtx(ctx); err != nil {
+				return err
+			}
+
+			current, err := r.findIDs(ctx, op.Filter, options.Find().SetSort(bson.D{
+				{Key: PositionFieldName, Value: 1},
+				{Key: f.ID, Value: 1},
+			}))
+			if err != nil {
+				return err
+			}
+
+			indexes, err := idIndexes(current, ids)
+			if err != nil {
+				return err
+			}
+			ordered := fn(current, indexes)
+			return r.writePositions(ctx, ordered, sequentialPositions(len(ordered)))
+		})
+
+		// The aborted transaction rolls back the owner's version too.
+		if err != nil && transactional && isVersioned {
+			versioned.SetVersion(version)
+		}
+		return err
+	})
+}
+
+// inTransaction runs fn in a transaction and commits it if fn returns
+// no error. if the server does not support transactions (e.g. it's a
+// standalone server), it runs fn without a transaction.
+func inTransaction(fn func(ctx context.Context) error) error {
+	err := mgm.TransactionWithCtx(mgm.Ctx(), func(session mongo.Session, sc mongo.SessionContext) error {
+		if err := fn(sc); err != nil {
+			_ = session.AbortTransaction(sc)
+			return err
+		}
+		return session.CommitTransaction(sc)
+	})
+	if isTransactionNotSupported(err) {
+		return fn(mgm.Ctx())
+	}
+	return err
+}
+
+// isTransactionNotSupported checks whether the error is returned
+// because the server does not support transactions.
+func isTransactionNotSupported(err error) bool {
+	const illegalOperationCode = 20
+
+	var cmdErr mongo.CommandError
+	return errors.As(err, &cmdErr) && cmdErr.Code == illegalOperationCode
+}
+
+// syncOrderedBatch syncs the models and writes their positions.
+func (r *HasManyRelation) syncOrderedBatch(models []mgm.Model, positions []int64) error {
+	for i, m := range models {
+		if positioned, ok := m.(Positioned); ok {
+			positioned.SetPosition(positions[i])
+		}
+	}
+
+	syncErr := r.syncBatch(models)
+	if syncErr != nil {
+		if _, ok := syncErr.(SyncErrors); !ok {
+			return syncErr
+		}
+	}
+
+	if err := r.writePositions(mgm.Ctx(), extractIDs(models), positions); err != nil {
+		return err
+	}
+	return syncErr
+}
+
+// appendPositions returns position of the related models with provided
+// ids. models that are related already keep their position, and others
+// are placed after the last related model in the same order.
+func (r *relation) appendPositions(ids []interface{}) ([]int64, error) {
+	next, err := r.nextPosition()
+	if err != nil {
+		return nil, err
+	}
+
+	filter := r.filterByRelation(nil)
+	filter[f.ID] = bson.M{o.In: ids}
+	filter[PositionFieldName] = bson.M{o.Exists: true}
+	cur, err := mgm.Coll(r.related).Find(mgm.Ctx(), filter, options.Find().SetProjection(bson.M{PositionFieldName: 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(mgm.Ctx())
+
+	current := make(map[string]int64)
+	for cur.Next(mgm.Ctx()) {
+		doc := struct {
+			ID       interface{} `bson:"_id"`
+			Position int64       `bson:"position"`
+		}{}
+		if err := cur.Decode(&doc); err != nil {
+			return nil, err
+		}
+		key, err := idKey(doc.ID)
+		if err != nil {
+			return nil, err
+		}
+		current[key] = doc.Position
+	}
+	if err := cur.Err(); err != nil {
+		return nil, err
+	}
+
+	positions := make([]int64, len(ids))
+	for i, id := range ids {
+		key, err := idKey(id)
+		if err != nil {
+			return nil, err
+		}
+		if position, ok := current[key]; ok {
+			positions[i] = position
+			continue
+		}
+		positions[i] = next
+		next++
+	}
+	return positions, nil
+}
+
+// attachOrdered sets the foreign key and position of the models
+// with provided ids by a single bulk write.
+func (r *relation) attachOrdered(ids []interface{}, positions []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	writes := make([]mongo.WriteModel, len(ids))
+	for i, id := range ids {
+		writes[i] = mongo.NewUpdateOneModel().
+			SetFilter(bson.M{f.ID: id}).
+			SetUpdate(bson.M{o.Set: bson.M{r.foreignKey: r.m.GetID(), PositionFieldName: positions[i]}})
+	}
+
+	_, err := mgm.Coll(r.related).BulkWrite(mgm.Ctx(), writes, options.BulkWrite().SetOrdered(false))
+	return err
+}
+
+// sequentialPositions returns n positions starting from zero.
+func sequentialPositions(n int) []int64 {
+	positions := make([]int64, n)
+	for i := range positions {
+		positions[i] = int64(i)
+	}
+	return positions
+}
+
+// nextPosition returns position after the last related model.
+func (r *relation) nextPosition() (int64, error) {
+	last := struct {
+		Position int64 `bson:"position"`
+	}{}
+	opts := options.FindOne().
+		SetSort(bson.M{PositionFieldName: -1}).
+		SetProjection(bson.M{PositionFieldName: 1})
+
+	err := mgm.Coll(r.related).FindOne(mgm.Ctx(), r.filterByRelation(nil), opts).Decode(&last)
+	if err == mongo.ErrNoDocuments {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return last.Position + 1, nil
+}
+
+// writePositions writes the positions of the related models with
+// provided ids by a single bulk write. the bulk write is not atomic.
+func (r *relation) writePositions(ctx context.Context, ids []interface{}, positions []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	writes := make([]mongo.WriteModel, len(ids))
+	for i, id := range ids {
+		writes[i] = mongo.NewUpdateOneModel().
+			SetFilter(bson.M{f.ID: id, r.foreignKey: r.m.GetID()}).
+			SetUpdate(bson.M{o.Set: bson.M{PositionFieldName: positions[i]}})
+	}
+
+	_, err := mgm.Coll(r.related).BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false))
+	return err
+}
+
+// idIndexes returns index of the ids in the list, or the
+// ModelsNotFoundError if some of them are not in the list.
+func idIndexes(list []interface{}, ids []interface{}) ([]int, error) {
+	positions := make(map[string]int, len(list))
+	for i, id := range list {
+		key, err := idKey(id)
+		if err != nil {
+			return nil, err
+		}
+		positions[key] = i
+	}
+
+	indexes := make([]int, 0, len(ids))
+	var missing []interface{}
+	for _, id := range ids {
+		key, err := idKey(id)
+		if err != nil {
+			return nil, err
+		}
+		i, ok := positions[key]
+		if !ok {
+			missing = append(missing, id)
+			continue
+		}
+		indexes = append(indexes, i)
+	}
+	if len(missing) != 0 {
+		return nil, &ModelsNotFoundError{IDs: missing}
+	}
+	return indexes, nil
+}
@@ -0,0 +1,202 @@
+package mgmrel_test
+
+import (
+	"errors"
+	mgmrel "github.com/kamva/mgm-relation"
+	"github.com/kamva/mgm/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"testing"
+)
+
+type OrderedAuthor struct {
+	DocAuthor            `bson:",inline"`
+	mgmrel.PositionField `bson:",inline"`
+}
+
+func (a *OrderedAuthor) CollectionName() string {
+	return "doc_authors"
+}
+
+func insertOrderedRelation(t *testing.T) (*Doc, []*OrderedAuthor) {
+	d := NewDoc("A", 12)
+	require.NoError(t, mgm.Coll(d).Create(d))
+
+	authors := []*OrderedAuthor{
+		{DocAuthor: *NewDocAuthor("B1", d.ID)},
+		{DocAuthor: *NewDocAuthor("B2", d.ID)},
+		{DocAuthor: *NewDocAuthor("B3", d.ID)},
+	}
+	require.NoError(t, mgmrel.HasMany(d, &OrderedAuthor{}).Ordered(true).Sync(authors))
+	return d, authors
+}
+
+func orderedNames(t *testing.T, d *Doc) []string {
+	names := make([]string, 0)
+	require.NoError(t, mgmrel.HasMany(d, &DocAuthor{}).Ordered(true).Pluck("name", &names))
+	return names
+}
+
+func TestHasManyRelation_Ordered_Sync(t *testing.T) {
+	setupDefConnection()
+	resetCollection()
+	d, authors := insertOrderedRelation(t)
+	for i, a := range authors {
+		assert.Equal(t, int64(i), a.Position)
+	}
+
+	// Models that are not Positioned get their position too.
+	reversed := []*DocAuthor{&authors[2].DocAuthor, &authors[1].DocAuthor, &authors[0].DocAuthor}
+	require.NoError(t, mgmrel.HasMany(d, &DocAuthor{}).Ordered(true).Sync(reversed))
+	assert.Equal(t, []string{"B3", "B2", "B1"}, orderedNames(t, d))
+
+	found := make([]*OrderedAuthor, 0)
+	require.NoError(t, mgmrel.HasMany(d, &OrderedAuthor{}).Ordered(true).SimpleGet(&found, 0))
+	require.Equal(t, 3, len(found))
+	assert.Equal(t, authors[2].ID, found[0].ID)
+	assert.Equal(t, int64(0), found[0].Position)
+}
+
+func TestHasManyRelation_Ordered_SyncWithoutRemove(t *testing.T) {
+	setupDefConnection()
+	resetCollection()
+	d, _ := insertOrderedRelation(t)
+
+	author := &OrderedAuthor{DocAuthor: *NewDocAuthor("B4", d.ID)}
+	require.NoError(t, mgmrel.HasMany(d, &OrderedAuthor{}).Ordered(true).SyncWithoutRemove([]*OrderedAuthor{author}))
+	assert.Equal(t, int64(3), author.Position)
+	assert.Equal(t, []string{"B1", "B2", "B3", "B4"}, orderedNames(t, d))
+}
+
+func TestHasManyRelation_Ordered_SyncWithoutRemove_Existing(t *testing.T) {
+	setupDefConnection()
+	resetCollection()
+	d, authors := insertOrderedRelation(t)
+	rel := mgmrel.HasMany(d, &OrderedAuthor{}).Ordered(true)
+
+	// Existing models keep their position, even if their position field is stale.
+	authors[0].Name = "B1-updated"
+	authors[0].Position = 0
+	authors[1].Position = 0
+	author := &OrderedAuthor{DocAuthor: *NewDocAuthor("B4", d.ID)}
+	require.NoError(t, rel.SyncWithoutRemove([]*OrderedAuthor{author, authors[1], authors[0]}))
+	assert.Equal(t, int64(0), authors[0].Position)
+	assert.Equal(t, int64(1), authors[1].Position)
+	assert.Equal(t, int64(3), author.Position)
+
+	require.NoError(t, mgmrel.HasMany(d, &DocAuthor{}).Ordered(true).Save(&authors[2].DocAuthor))
+	assert.Equal(t, []string{"B1-updated", "B2", "B3", "B4"}, orderedNames(t, d))
+}
+
+func TestHasManyRelation_Ordered_Create(t *testing.T) {
+	setupDefConnection()
+	resetCollection()
+	d, _ := insertOrderedRelation(t)
+
+	author := &OrderedAuthor{DocAuthor: DocAuthor{Name: "B4"}}
+	require.NoError(t, mgmrel.HasMany(d, &OrderedAuthor{}).Ordered(true).Create(author))
+	assert.Equal(t, int64(3), author.Position)
+
+	// Models that are not Positioned get their position too.
+	require.NoError(t, mgmrel.HasMany(d, &DocAuthor{}).Ordered(true).CreateMany([]*DocAuthor{{Name: "B5"}, {Name: "B6"}}))
+	assert.Equal(t, []string{"B1", "B2", "B3", "B4", "B5", "B6"}, orderedNames(t, d))
+}
+
+func TestHasManyRelation_Ordered_Attach(t *testing.T) {
+	setupDefConnection()
+	resetCollection()
+	d, authors := insertOrderedRelation(t)
+	res, err := mgm.Coll(&DocAuthor{}).InsertOne(mgm.Ctx(), bson.M{"name": "B4"})
+	require.NoError(t, err)
+
+	require.NoError(t, mgmrel.HasMany(d, &DocAuthor{}).Ordered(true).Attach(res.InsertedID, authors[0].ID))
+	assert.Equal(t, []string{"B1", "B2", "B3", "B4"}, orderedNames(t, d))
+}
+
+func TestHasManyRelation_Ordered_KeepsDefaultSort(t *testing.T) {
+	setupDefConnection()
+	resetCollection()
+	d, _ := insertOrderedRelation(t)
+
+	names := make([]string, 0)
+	rel := mgmrel.HasMany(d, &DocAuthor{}).DefaultSort("-name").Ordered(true)
+	require.NoError(t, rel.Pluck("name", &names))
+	assert.Equal(t, []string{"B3", "B2", "B1"}, names)
+
+	names = make([]string, 0)
+	require.NoError(t, rel.Ordered(false).Pluck("name", &names))
+	assert.Equal(t, []string{"B3", "B2", "B1"}, names)
+}
+
+func TestHasManyRelation_Ordered_Move(t *testing.T) {
+	setupDefConnection()
+	resetCollection()
+	d, authors := insertOrderedRelation(t)
+
+	other := NewDoc("B", 13)
+	require.NoError(t, mgm.Coll(other).Create(other))
+	require.NoError(t, mgmrel.HasMany(other, &OrderedAuthor{}).Ordered(true).Create(&OrderedAuthor{DocAuthor: DocAuthor{Name: "C1"}}))
+
+	rel := mgmrel.HasMany(d, &OrderedAuthor{}).Ordered(true)
+	require.NoError(t, rel.Move([]interface{}{authors[1].ID, authors[0].ID}, other))
+	assert.Equal(t, []string{"B3"}, orderedNames(t, d))
+	assert.Equal(t, []string{"C1", "B2", "B1"}, orderedNames(t, other))
+}
+
+func TestHasManyRelation_MoveTo(t *testing.T) {
+	setupDefConnection()
+	resetCollection()
+	d, authors := insertOrderedRelation(t)
+	rel := mgmrel.HasMany(d, &OrderedAuthor{}).Ordered(true)
+
+	require.NoError(t, rel.MoveTo(authors[2].ID, 0))
+	assert.Equal(t, []string{"B3", "B1", "B2"}, orderedNames(t, d))
+
+	require.NoError(t, rel.MoveTo(authors[2].ID, 10))
+	assert.Equal(t, []string{"B1", "B2", "B3"}, orderedNames(t, d))
+}
+
+func TestHasManyRelation_Swap(t *testing.T) {
+	setupDefConnection()
+	resetCollection()
+	d, authors := insertOrderedRelation(t)
+
+	require.NoError(t, mgmrel.HasMany(d, &OrderedAuthor{}).Ordered(true).Swap(authors[0].ID, authors[2].ID))
+	assert.Equal(t, []string{"B3", "B2", "B1"}, orderedNames(t, d))
+}
+
+func TestHasManyRelation_Reorder(t *testing.T) {
+	setupDefConnection()
+	resetCollection()
+	d, authors := insertOrderedRelation(t)
+	rel := mgmrel.HasMany(d, &OrderedAuthor{}).Ordered(true)
+
+	require.NoError(t, rel.Reorder(authors[1].ID, authors[2].ID))
+	assert.Equal(t, []string{"B2", "B3", "B1"}, orderedNames(t, d))
+
+	err := rel.Reorder(primitive.NewObjectID())
+	assert.True(t, errors.Is(err, mgmrel.ErrModelsNotFound))
+}
+
+func TestHasManyRelation_Reorder_NotOrdered(t *testing.T) {
+	err := mgmrel.HasMany(NewDoc("A", 12), &DocAuthor{}).Reorder(primitive.NewObjectID())
+	assert.Equal(t, mgmrel.ErrNotOrdered, err)
+}
+
+func TestHasManyRelation_Reorder_VersionedOwner(t *testing.T) {
+	setupDefConnection()
+	resetCollection()
+	d := &VersionedDoc{Name: "A"}
+	require.NoError(t, mgm.Coll(d).Create(d))
+	authors := []*DocAuthor{NewDocAuthor("B1", d.ID), NewDocAuthor("B2", d.ID)}
+	require.NoError(t, mgmrel.HasManyWithOptions(d, &DocAuthor{}, "doc_id").Ordered(true).Sync(authors))
+	staleDoc := *d
+
+	require.NoError(t, mgmrel.HasManyWithOptions(d, &DocAuthor{}, "doc_id").Ordered(true).Swap(authors[0].ID, authors[1].ID))
+
+	// A reorder that read the owner before the swap conflicts.
+	err := mgmrel.HasManyWithOptions(&staleDoc, &DocAuthor{}, "doc_id").Ordered(true).Reorder(authors[0].ID)
+	assert.True(t, errors.Is(err, mgmrel.ErrConcurrentModification))
+}
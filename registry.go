@@ -0,0 +1,91 @@
+package mgmrel
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/kamva/mgm/v3"
+)
+
+// Relation is the interface that both "has one" and "has many"
+// relations implement.
+type Relation interface {
+	// Info returns the relation's metadata.
+	Info() RelationInfo
+	// MissingIndexes returns the relation's indexes that do not exist.
+	MissingIndexes(ctx context.Context) ([]Index, error)
+	// EnsureIndexes creates the relation's indexes if they do not exist.
+	EnsureIndexes(ctx context.Context) error
+
+	base() *relation
+}
+
+var _ Relation = &HasOneRelation{}
+var _ Relation = &HasManyRelation{}
+
+type registeredRelation struct {
+	name     string
+	relation Relation
+}
+
+var registry = struct {
+	sync.RWMutex
+	// relations contains the registered relations by their owner's type.
+	relations map[reflect.Type][]registeredRelation
+	// order keeps the registration order of the owner types.
+	order []reflect.Type
+}{relations: make(map[reflect.Type][]registeredRelation)}
+
+// Register registers the relation by name for its owner model's type.
+// the relation can be built using an empty owner model. e.g:
+// mgmrel.Register("Authors", mgmrel.HasMany(&Doc{}, &DocAuthor{}))
+func Register(name string, r Relation) {
+	t := modelType(r.Info().Owner)
+
+	registry.Lock()
+	defer registry.Unlock()
+
+	if _, ok := registry.relations[t]; !ok {
+		registry.order = append(registry.order, t)
+	}
+	relations := registry.relations[t]
+	for i, rel := range relations {
+		if rel.name == name {
+			relations[i].relation = r
+			return
+		}
+	}
+	registry.relations[t] = append(relations, registeredRelation{name: name, relation: r})
+}
+
+// RegisteredRelation returns the relation that registered by the
+// name for the owner model's type.
+func RegisteredRelation(owner mgm.Model, name string) (Relation, error) {
+	t := modelType(owner)
+
+	registry.RLock()
+	defer registry.RUnlock()
+
+	for _, rel := range registry.relations[t] {
+		if rel.name == name {
+			return rel.relation, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s.%s", ErrRelationNotFound, t.Name(), name)
+}
+
+// registeredRelations returns all of the registered relations.
+func registeredRelations() []Relation {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	var relations []Relation
+	for _, t := range registry.order {
+		for _, rel := range registry.relations[t] {
+			relations = append(relations, rel.relation)
+		}
+	}
+	return relations
+}
@@ -0,0 +1,32 @@
+package mgmrel_test
+
+import (
+	"errors"
+	mgmrel "github.com/kamva/mgm-relation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+type RegistryDoc struct {
+	mgmrel.IDField `bson:",inline"`
+}
+
+func TestRegister(t *testing.T) {
+	authors := mgmrel.HasMany(&RegistryDoc{}, &DocAuthor{})
+	mgmrel.Register("Authors", authors)
+
+	rel, err := mgmrel.RegisteredRelation(&RegistryDoc{}, "Authors")
+	require.NoError(t, err)
+	assert.Equal(t, authors, rel)
+
+	// Register again overrides the relation.
+	newAuthors := mgmrel.HasMany(&RegistryDoc{}, &DocAuthor{}).DefaultSort("name")
+	mgmrel.Register("Authors", newAuthors)
+	rel, err = mgmrel.RegisteredRelation(&RegistryDoc{}, "Authors")
+	require.NoError(t, err)
+	assert.Equal(t, newAuthors, rel)
+
+	_, err = mgmrel.RegisteredRelation(&RegistryDoc{}, "Profile")
+	assert.True(t, errors.Is(err, mgmrel.ErrRelationNotFound))
+}
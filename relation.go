@@ -0,0 +1,155 @@
+package mgmrel
+
+import (
+	"reflect"
+
+	"github.com/kamva/mgm/v3"
+	f "github.com/kamva/mgm/v3/field"
+	o "github.com/kamva/mgm/v3/operator"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// relation contains the fields and helpers that are shared between
+// the "has one" and "has many" relations.
+type relation struct {
+	kind    RelationKind
+	m       mgm.Model
+	related mgm.Model
+	// foreignKey uses in filters.
+	foreignKey string
+
+	// scope is the default filter that applies on reading the related models.
+	scope bson.M
+	// withoutScopes disables the scope filter.
+	withoutScopes bool
+	// sort is the default sort field. e.g `-created_at`
+	sort string
+	// projection is the default projection of the related models.
+	projection interface{}
+	// eagerLimit limits number of the related models that eager
+	// loading loads per owner.
+	eagerLimit int64
+
+	// allowReparent lets sync move models that belong to
+	// another owner to this relation's owner.
+	allowReparent bool
+
+	// mgmHooks enables dispatching the mgm model hooks on sync.
+	mgmHooks bool
+
+	// middlewares wrap the relation's operations.
+	middlewares []Middleware
+
+	// trackChanges enables writing just the changed fields of the
+	// models that we have their snapshot.
+	trackChanges bool
+	// snapshots contains snapshot of the loaded models by their id.
+	snapshots map[string]snapshot
+}
+
+// base returns the relation itself. it lets the package access the
+// relation's fields from the Relation interface.
+func (r *relation) base() *relation {
+	return r
+}
+
+// filterByRelation returns filter to find related models, except
+// models with provided ids.
+func (r *relation) filterByRelation(exceptIDs []interface{}) bson.M {
+	filter := bson.M{r.foreignKey: r.m.GetID()}
+	if len(exceptIDs) != 0 {
+		filter[f.ID] = bson.M{o.Nin: exceptIDs}
+	}
+
+	return filter
+}
+
+// scopedFilter returns filter to read related models. it's the
+// relation filter merged with the relation's scope.
+func (r *relation) scopedFilter() bson.M {
+	filter := r.filterByRelation(nil)
+	for k, v := range r.activeScope() {
+		filter[k] = v
+	}
+	return filter
+}
+
+// activeScope returns the relation's scope, unless scopes are disabled.
+func (r *relation) activeScope() bson.M {
+	scope := bson.M{}
+	if r.withoutScopes {
+		return scope
+	}
+
+	for k, v := range r.scope {
+		// We never let the scope override the relation's foreign key.
+		if k == r.foreignKey {
+			continue
+		}
+		scope[k] = v
+	}
+	return scope
+}
+
+// newRelatedModel returns new instance of the related model's type.
+func (r *relation) newRelatedModel() mgm.Model {
+	return reflect.New(modelType(r.related)).Interface().(mgm.Model)
+}
+
+// findRelated finds the related models that match the filter.
+func (r *relation) findRelated(filter interface{}, opts ...*options.FindOptions) ([]mgm.Model, error) {
+	cur, err := mgm.Coll(r.related).Find(mgm.Ctx(), filter, opts...)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(mgm.Ctx())
+
+	models := make([]mgm.Model, 0)
+	for cur.Next(mgm.Ctx()) {
+		m := r.newRelatedModel()
+		if err := cur.Decode(m); err != nil {
+			return nil, err
+		}
+		models = append(models, m)
+	}
+	return models, cur.Err()
+}
+
+// findOptions returns the relation's default find options.
+func (r *relation) findOptions() *options.FindOptions {
+	opts := options.Find()
+	if r.sort != "" {
+		opts.SetSort(sortFieldToBsonD(r.sort))
+	}
+	if r.projection != nil {
+		opts.SetProjection(r.projection)
+	}
+	return opts
+}
+
+// findOneOptions returns the relation's default find one options.
+func (r *relation) findOneOptions() *options.FindOneOptions {
+	opts := options.FindOne()
+	if r.sort != "" {
+		opts.SetSort(sortFieldToBsonD(r.sort))
+	}
+	if r.projection != nil {
+		opts.SetProjection(r.projection)
+	}
+	return opts
+}
+
+// sortFieldToBsonD converts the string sort field to bson D.
+func sortFieldToBsonD(field string) bson.D {
+	// Ascending order
+	order := 1
+	if field[0] == '-' {
+		order = -1
+		field = field[1:]
+	}
+
+	return bson.D{
+		{Key: field, Value: order},
+	}
+}
@@ -0,0 +1,44 @@
+package mgmrel
+
+import (
+	"github.com/kamva/gutil"
+	"github.com/kamva/mgm/v3"
+	f "github.com/kamva/mgm/v3/field"
+	o "github.com/kamva/mgm/v3/operator"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// SequenceCollection is name of the collection that keeps the sequences.
+var SequenceCollection = "counters"
+
+// sequenceBinder is implemented by id fields that need to know
+// the model's sequence name (e.g Int64IDField).
+type sequenceBinder interface {
+	bindSequence(name string)
+}
+
+type sequence struct {
+	Name  string `bson:"_id"`
+	Value int64  `bson:"seq"`
+}
+
+// NextSequence increments the sequence and returns its new value.
+// it creates the sequence if it does not exist.
+func NextSequence(name string) (int64, error) {
+	opts := &options.FindOneAndUpdateOptions{
+		Upsert: gutil.NewBool(true),
+	}
+	opts.SetReturnDocument(options.After)
+
+	seq := &sequence{}
+	res := mgm.CollectionByName(SequenceCollection).FindOneAndUpdate(mgm.Ctx(),
+		bson.M{f.ID: name},
+		bson.M{o.Inc: bson.M{"seq": 1}},
+		opts,
+	)
+	if err := res.Decode(seq); err != nil {
+		return 0, err
+	}
+	return seq.Value, nil
+}
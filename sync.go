@@ -0,0 +1,177 @@
+package mgmrel
+
+import (
+	"github.com/kamva/gutil"
+	"github.com/kamva/mgm/v3"
+	f "github.com/kamva/mgm/v3/field"
+	o "github.com/kamva/mgm/v3/operator"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// InsertOnlyFields is the interface to implement by models that have
+// fields which must be written just on inserting the model.
+// those fields are written by the `$setOnInsert` operator instead of
+// the `$set`, so syncing an existing model never overwrites them.
+// you can also use the `mgmrel:"insertOnly"` tag on the fields.
+type InsertOnlyFields interface {
+	// InsertOnlyFields returns bson name of the fields.
+	InsertOnlyFields() []string
+}
+
+// syncModel calls to the sync hooks and upserts the model. if the
+// relation tracks changes, it skips models that are not changed.
+func (r *relation) syncModel(m mgm.Model) error {
+	if unchanged, err := r.unchanged(m); err != nil || unchanged {
+		return err
+	}
+	if err := r.beforeSync(m); err != nil {
+		return err
+	}
+	res, err := r.write(m)
+	if err != nil {
+		return err
+	}
+	return r.afterSync(m, res)
+}
+
+// beforeSync calls to the before sync hooks of the model. if the relation
+// dispatches mgm hooks, it also calls to the mgm creating or updating hooks.
+func (r *relation) beforeSync(m mgm.Model) error {
+	if err := callToBeforeSyncHooks(m); err != nil {
+		return err
+	}
+	if !r.mgmHooks {
+		return nil
+	}
+
+	exists, err := r.exists(m.GetID())
+	if err != nil {
+		return err
+	}
+	if exists {
+		return callToMgmBeforeUpdateHooks(m)
+	}
+	return callToMgmBeforeCreateHooks(m)
+}
+
+// afterSync calls to the after sync hooks of the model. if the relation
+// dispatches mgm hooks, it also calls to the mgm created or updated hooks
+// based on the upsert result.
+func (r *relation) afterSync(m mgm.Model, res *mongo.UpdateResult) error {
+	if r.mgmHooks {
+		var err error
+		if res.UpsertedCount != 0 {
+			err = callToMgmAfterCreateHooks(m)
+		} else {
+			err = callToMgmAfterUpdateHooks(res, m)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return callToAfterSyncHooks(m)
+}
+
+// exists checks whether a related model with the provided id exists.
+func (r *relation) exists(id interface{}) (bool, error) {
+	count, err := mgm.Coll(r.related).CountDocuments(mgm.Ctx(), bson.M{f.ID: id}, options.Count().SetLimit(1))
+	return count != 0, err
+}
+
+// upsert updates the model, or inserts it if it does not exist.
+// if the model is versioned, it updates the model just if its version
+// is not changed, and increments the version.
+func (r *relation) upsert(m mgm.Model) (*mongo.UpdateResult, error) {
+	update, err := upsertUpdate(m)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := bson.M{f.ID: m.GetID()}
+	versioned, isVersioned := m.(Versioned)
+	if isVersioned {
+		filter[VersionFieldName] = versioned.GetVersion()
+	}
+
+	res, err := mgm.Coll(r.related).UpdateOne(mgm.Ctx(), filter, update, &options.UpdateOptions{
+		Upsert: gutil.NewBool(true),
+	})
+	if err != nil {
+		if isVersioned && isDuplicateKeyError(err) {
+			return nil, r.versionConflict(m, versioned, err)
+		}
+		return nil, err
+	}
+
+	if isVersioned {
+		versioned.SetVersion(versioned.GetVersion() + 1)
+	}
+	return res, nil
+}
+
+// upsertUpdate returns the update document to upsert the model.
+func upsertUpdate(m mgm.Model) (bson.M, error) {
+	insertOnly := insertOnlyFields(m)
+	readonly := modelFieldTags(m).readonly
+	_, versioned := m.(Versioned)
+
+	if len(insertOnly) == 0 && len(readonly) == 0 && !versioned {
+		return bson.M{o.Set: m}, nil
+	}
+
+	doc, err := toBsonD(m)
+	if err != nil {
+		return nil, err
+	}
+
+	set := bson.D{}
+	setOnInsert := bson.D{}
+	for _, e := range doc {
+		switch {
+		case versioned && e.Key == VersionFieldName:
+			// We increment the version field.
+		case gutil.Contains(readonly, e.Key):
+			// The relation never writes the readonly fields.
+		case gutil.Contains(insertOnly, e.Key):
+			setOnInsert = append(setOnInsert, e)
+		default:
+			set = append(set, e)
+		}
+	}
+
+	update := bson.M{o.Set: set}
+	if len(setOnInsert) != 0 {
+		update[o.SetOnInsert] = setOnInsert
+	}
+	if versioned {
+		update[o.Inc] = bson.M{VersionFieldName: 1}
+	}
+	return update, nil
+}
+
+// insertOnlyFields returns the model's insert-only fields, from
+// both the InsertOnlyFields interface and the fields tags.
+func insertOnlyFields(m mgm.Model) []string {
+	fields := append([]string{}, modelFieldTags(m).insertOnly...)
+	if fielder, ok := m.(InsertOnlyFields); ok {
+		fields = append(fields, fielder.InsertOnlyFields()...)
+	}
+	return fields
+}
+
+// toBsonD converts the model to the bson document.
+func toBsonD(m mgm.Model) (bson.D, error) {
+	b, err := bson.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := bson.D{}
+	if err := bson.Unmarshal(b, &doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
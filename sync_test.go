@@ -0,0 +1,57 @@
+package mgmrel_test
+
+import (
+	mgmrel "github.com/kamva/mgm-relation"
+	"github.com/kamva/mgm/v3"
+	f "github.com/kamva/mgm/v3/field"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"testing"
+	"time"
+)
+
+type DatedAuthor struct {
+	mgmrel.DefaultModel `bson:",inline"`
+
+	Name  string             `bson:"name"`
+	DocID primitive.ObjectID `bson:"doc_id"`
+}
+
+func resetDatedAuthors(t *testing.T) {
+	_, err := mgm.Coll(&DatedAuthor{}).DeleteMany(mgm.Ctx(), bson.M{})
+	require.NoError(t, err)
+}
+
+func TestSync_DateFields(t *testing.T) {
+	setupDefConnection()
+	resetCollection()
+	resetDatedAuthors(t)
+
+	d := NewDoc("A", 12)
+	require.NoError(t, mgm.Coll(d).Create(d))
+
+	author := &DatedAuthor{Name: "B1", DocID: d.ID}
+	require.NoError(t, mgmrel.HasOne(d, &DatedAuthor{}).Sync(author))
+	assert.False(t, author.ID.IsZero())
+	assert.False(t, author.CreatedAt.IsZero())
+	assert.False(t, author.UpdatedAt.IsZero())
+
+	found := &DatedAuthor{}
+	require.NoError(t, mgm.Coll(found).First(bson.M{f.ID: author.ID}, found))
+	createdAt := found.CreatedAt
+
+	time.Sleep(5 * time.Millisecond)
+
+	// Sync a fresh struct of the same model, created_at must not change.
+	updated := &DatedAuthor{Name: "B2", DocID: d.ID}
+	updated.ID = author.ID
+	require.NoError(t, mgmrel.HasOne(d, &DatedAuthor{}).Sync(updated))
+
+	found = &DatedAuthor{}
+	require.NoError(t, mgm.Coll(found).First(bson.M{f.ID: author.ID}, found))
+	assert.Equal(t, "B2", found.Name)
+	assert.Equal(t, createdAt, found.CreatedAt)
+	assert.True(t, found.UpdatedAt.After(createdAt))
+}
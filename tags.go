@@ -0,0 +1,138 @@
+package mgmrel
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/kamva/mgm/v3"
+)
+
+// TagName is the struct tag name that relations read the fields options from.
+// supported options are `insertOnly` (or `immutable`) to write the field just
+// on insert (using `$setOnInsert`), and `readonly` to never write the field.
+// e.g: CreatedBy string `bson:"created_by" mgmrel:"immutable"`
+const TagName = "mgmrel"
+
+const (
+	tagInsertOnly = "insertOnly"
+	tagImmutable  = "immutable"
+	tagReadonly   = "readonly"
+)
+
+// fieldTags contains bson name of the fields that have relation tags.
+type fieldTags struct {
+	insertOnly []string
+	readonly   []string
+}
+
+var fieldTagsCache sync.Map
+
+// modelFieldTags returns the model's field tags.
+func modelFieldTags(m mgm.Model) *fieldTags {
+	t := modelType(m)
+	if cached, ok := fieldTagsCache.Load(t); ok {
+		return cached.(*fieldTags)
+	}
+
+	tags := &fieldTags{}
+	if t.Kind() == reflect.Struct {
+		parseFieldTags(t, tags)
+	}
+	fieldTagsCache.Store(t, tags)
+	return tags
+}
+
+// parseFieldTags parses the struct's fields tags, including its inline fields.
+func parseFieldTags(t reflect.Type, tags *fieldTags) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, inline, skip := bsonFieldName(field)
+		if skip {
+			continue
+		}
+
+		if inline {
+			ft := field.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				parseFieldTags(ft, tags)
+			}
+			continue
+		}
+
+		for _, opt := range strings.Split(field.Tag.Get(TagName), ",") {
+			switch strings.TrimSpace(opt) {
+			case tagInsertOnly, tagImmutable:
+				tags.insertOnly = append(tags.insertOnly, name)
+			case tagReadonly:
+				tags.readonly = append(tags.readonly, name)
+			}
+		}
+	}
+}
+
+// bsonFieldName returns the field's bson name the same way as the
+// mongo driver's default struct tag parser.
+func bsonFieldName(field reflect.StructField) (name string, inline bool, skip bool) {
+	if field.PkgPath != "" && !field.Anonymous {
+		// Unexported field.
+		return "", false, true
+	}
+
+	tag, ok := field.Tag.Lookup("bson")
+	if !ok && !strings.Contains(string(field.Tag), ":") && len(field.Tag) > 0 {
+		tag = string(field.Tag)
+	}
+	if tag == "-" {
+		return "", false, true
+	}
+
+	parts := strings.Split(tag, ",")
+	name = parts[0]
+	for _, opt := range parts[1:] {
+		if opt == "inline" {
+			inline = true
+		}
+	}
+	if name == "" {
+		name = strings.ToLower(field.Name)
+	}
+	return name, inline, false
+}
+
+// fieldByBsonName returns the struct's field with the provided
+// bson name, including its inline fields.
+func fieldByBsonName(v reflect.Value, name string) (reflect.Value, bool) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		fieldName, inline, skip := bsonFieldName(t.Field(i))
+		if skip {
+			continue
+		}
+
+		field := v.Field(i)
+		if inline {
+			if field.Kind() == reflect.Ptr {
+				if field.IsNil() {
+					continue
+				}
+				field = field.Elem()
+			}
+			if field.Kind() != reflect.Struct {
+				continue
+			}
+			if found, ok := fieldByBsonName(field, name); ok {
+				return found, true
+			}
+			continue
+		}
+
+		if fieldName == name {
+			return field, true
+		}
+	}
+	return reflect.Value{}, false
+}
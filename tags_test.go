@@ -0,0 +1,73 @@
+package mgmrel_test
+
+import (
+	mgmrel "github.com/kamva/mgm-relation"
+	"github.com/kamva/mgm/v3"
+	f "github.com/kamva/mgm/v3/field"
+	o "github.com/kamva/mgm/v3/operator"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"testing"
+)
+
+type TaggedAuthor struct {
+	mgmrel.IDField `bson:",inline"`
+
+	Name      string             `bson:"name"`
+	DocID     primitive.ObjectID `bson:"doc_id"`
+	CreatedBy string             `bson:"created_by" mgmrel:"immutable"`
+	Score     int                `bson:"score" mgmrel:"readonly"`
+}
+
+func TestFieldTags_Sync(t *testing.T) {
+	setupDefConnection()
+	resetCollection()
+	_, err := mgm.Coll(&TaggedAuthor{}).DeleteMany(mgm.Ctx(), bson.M{})
+	require.NoError(t, err)
+
+	d := NewDoc("A", 12)
+	require.NoError(t, mgm.Coll(d).Create(d))
+
+	author := &TaggedAuthor{Name: "B1", DocID: d.ID, CreatedBy: "ali", Score: 10}
+	rel := mgmrel.HasMany(d, &TaggedAuthor{})
+	require.NoError(t, rel.SyncWithoutRemove([]*TaggedAuthor{author}))
+
+	found := &TaggedAuthor{}
+	require.NoError(t, mgm.Coll(found).First(bson.M{f.ID: author.ID}, found))
+	assert.Equal(t, "ali", found.CreatedBy)
+	assert.Equal(t, 0, found.Score)
+
+	// Another service sets the readonly field.
+	_, err = mgm.Coll(found).UpdateOne(mgm.Ctx(), bson.M{f.ID: author.ID}, bson.M{o.Set: bson.M{"score": 5}})
+	require.NoError(t, err)
+
+	// Sync a struct that did not load the immutable and readonly fields.
+	partial := &TaggedAuthor{Name: "B2", DocID: d.ID}
+	partial.ID = author.ID
+	require.NoError(t, rel.SyncWithoutRemove([]*TaggedAuthor{partial}))
+
+	require.NoError(t, mgm.Coll(found).First(bson.M{f.ID: author.ID}, found))
+	assert.Equal(t, "B2", found.Name)
+	assert.Equal(t, "ali", found.CreatedBy)
+	assert.Equal(t, 5, found.Score)
+}
+
+func TestFieldTags_Create(t *testing.T) {
+	setupDefConnection()
+	resetCollection()
+	_, err := mgm.Coll(&TaggedAuthor{}).DeleteMany(mgm.Ctx(), bson.M{})
+	require.NoError(t, err)
+
+	d := NewDoc("A", 12)
+	require.NoError(t, mgm.Coll(d).Create(d))
+
+	author := &TaggedAuthor{Name: "B1", CreatedBy: "ali", Score: 10}
+	require.NoError(t, mgmrel.HasMany(d, &TaggedAuthor{}).Create(author))
+
+	found := &TaggedAuthor{}
+	require.NoError(t, mgm.Coll(found).First(bson.M{f.ID: author.ID}, found))
+	assert.Equal(t, "ali", found.CreatedBy)
+	assert.Equal(t, 0, found.Score)
+}
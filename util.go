@@ -1,15 +1,44 @@
 package mgmrel
 
 import (
-	"fmt"
+	"reflect"
+
+	"github.com/jinzhu/inflection"
 	"github.com/kamva/gutil"
 	"github.com/kamva/mgm/v3"
-	"reflect"
 )
 
-// foreignKeyName gets the Model and returns foreignKey field name.
-// e.g bet the "Book" model and returns "book_id".
+// foreignKeyName gets the Model and returns foreignKey field name
+// using the global naming strategy.
+// e.g get the "Book" model and returns "book_id".
 func foreignKeyName(m mgm.Model) string {
-	name := reflect.TypeOf(m).Elem().Name()
-	return fmt.Sprintf("%s_id", gutil.ToSnakeCase(name))
+	return defaultNamingStrategy.ForeignKey(m)
+}
+
+// modelType returns the underlying (non-pointer) type of the model.
+func modelType(m mgm.Model) reflect.Type {
+	t := reflect.TypeOf(m)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// modelTypeName returns name of the model's type, whether
+// the model is passed by value or by pointer.
+func modelTypeName(m mgm.Model) string {
+	return modelType(m).Name()
+}
+
+// collectionName returns model's collection name. unlike the mgm.CollName
+// it does not panic for models that are not pointers.
+func collectionName(m mgm.Model) string {
+	if collGetter, ok := m.(mgm.CollectionGetter); ok {
+		return collGetter.Collection().Name()
+	}
+	if collNameGetter, ok := m.(mgm.CollectionNameGetter); ok {
+		return collNameGetter.CollectionName()
+	}
+
+	return inflection.Plural(gutil.ToSnakeCase(modelTypeName(m)))
 }
@@ -0,0 +1,102 @@
+package mgmrel
+
+import (
+	"context"
+
+	"github.com/kamva/mgm/v3"
+	f "github.com/kamva/mgm/v3/field"
+	o "github.com/kamva/mgm/v3/operator"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// VersionFieldName is bson name of the version field.
+const VersionFieldName = "version"
+
+// Versioned is the interface to implement by models that use optimistic
+// concurrency control. relations write a versioned related model just if
+// its version in the DB is the same as the model's version, otherwise
+// they return ErrConcurrentModification. you can embed the VersionField
+// in your model to implement it.
+//
+// If the owner model is versioned too, sync methods also check and
+// increment the owner's version, so concurrent syncs of the same
+// owner's related models conflict.
+type Versioned interface {
+	GetVersion() int64
+	SetVersion(v int64)
+}
+
+// VersionField struct contain model's version field.
+type VersionField struct {
+	Version int64 `json:"version" bson:"version"`
+}
+
+// GetVersion returns the model's version.
+func (f *VersionField) GetVersion() int64 {
+	return f.Version
+}
+
+// SetVersion sets the model's version.
+func (f *VersionField) SetVersion(v int64) {
+	f.Version = v
+}
+
+var _ Versioned = &VersionField{}
+
+// lockOwner checks and increments the owner's version if it's versioned.
+func (r *relation) lockOwner() error {
+	if _, ok := r.m.(Versioned); !ok {
+		return nil
+	}
+	return r.lockOwnerWithCtx(mgm.Ctx())
+}
+
+// lockOwnerWithCtx is the same as lockOwner, but runs the update
+// with the provided context.
+func (r *relation) lockOwnerWithCtx(ctx context.Context) error {
+	versioned, ok := r.m.(Versioned)
+	if !ok {
+		return nil
+	}
+
+	filter := bson.M{f.ID: r.m.GetID(), VersionFieldName: versioned.GetVersion()}
+	res, err := mgm.Coll(r.m).UpdateOne(ctx, filter, bson.M{o.Inc: bson.M{VersionFieldName: 1}})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return &ConcurrentModificationError{ID: r.m.GetID(), Version: versioned.GetVersion()}
+	}
+
+	versioned.SetVersion(versioned.GetVersion() + 1)
+	return nil
+}
+
+// versionConflict returns the concurrent modification error if the
+// model's upsert failed because of its version, otherwise returns the
+// original error.
+func (r *relation) versionConflict(m mgm.Model, versioned Versioned, err error) error {
+	exists, existsErr := r.exists(m.GetID())
+	if existsErr != nil || !exists {
+		return err
+	}
+	return &ConcurrentModificationError{ID: m.GetID(), Version: versioned.GetVersion()}
+}
+
+// isDuplicateKeyError checks whether the error is the mongo duplicate key error.
+func isDuplicateKeyError(err error) bool {
+	const duplicateKeyCode = 11000
+
+	switch e := err.(type) {
+	case mongo.WriteException:
+		for _, we := range e.WriteErrors {
+			if we.Code == duplicateKeyCode {
+				return true
+			}
+		}
+	case mongo.CommandError:
+		return e.Code == duplicateKeyCode
+	}
+	return false
+}
@@ -0,0 +1,88 @@
+package mgmrel_test
+
+import (
+	"errors"
+	mgmrel "github.com/kamva/mgm-relation"
+	"github.com/kamva/mgm/v3"
+	f "github.com/kamva/mgm/v3/field"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"testing"
+)
+
+type VersionedAuthor struct {
+	mgmrel.IDField      `bson:",inline"`
+	mgmrel.VersionField `bson:",inline"`
+
+	Name  string             `bson:"name"`
+	DocID primitive.ObjectID `bson:"doc_id"`
+}
+
+type VersionedDoc struct {
+	mgmrel.IDField      `bson:",inline"`
+	mgmrel.VersionField `bson:",inline"`
+
+	Name string `bson:"name"`
+}
+
+func (d *VersionedDoc) CollectionName() string {
+	return "docs"
+}
+
+func resetVersionedAuthors(t *testing.T) {
+	_, err := mgm.Coll(&VersionedAuthor{}).DeleteMany(mgm.Ctx(), bson.M{})
+	require.NoError(t, err)
+}
+
+func TestVersionField_Sync(t *testing.T) {
+	setupDefConnection()
+	resetCollection()
+	resetVersionedAuthors(t)
+
+	d := NewDoc("A", 12)
+	require.NoError(t, mgm.Coll(d).Create(d))
+
+	author := &VersionedAuthor{Name: "B1", DocID: d.ID}
+	rel := mgmrel.HasMany(d, &VersionedAuthor{})
+	require.NoError(t, rel.SyncWithoutRemove([]*VersionedAuthor{author}))
+	assert.Equal(t, int64(1), author.Version)
+
+	stale := *author
+
+	author.Name = "B2"
+	require.NoError(t, rel.SyncWithoutRemove([]*VersionedAuthor{author}))
+	assert.Equal(t, int64(2), author.Version)
+
+	stale.Name = "B3"
+	err := rel.SyncWithoutRemove([]*VersionedAuthor{&stale})
+	require.True(t, errors.Is(err, mgmrel.ErrConcurrentModification))
+
+	found := &VersionedAuthor{}
+	require.NoError(t, mgm.Coll(found).First(bson.M{f.ID: author.ID}, found))
+	assert.Equal(t, "B2", found.Name)
+	assert.Equal(t, int64(2), found.Version)
+}
+
+func TestVersionField_OwnerSync(t *testing.T) {
+	setupDefConnection()
+	resetCollection()
+	resetVersionedAuthors(t)
+
+	d := &VersionedDoc{Name: "A"}
+	require.NoError(t, mgm.Coll(d).Create(d))
+	staleDoc := *d
+
+	author := &VersionedAuthor{Name: "B1", DocID: d.ID}
+	require.NoError(t, mgmrel.HasMany(d, &VersionedAuthor{}).Sync([]*VersionedAuthor{author}))
+	assert.Equal(t, int64(1), d.Version)
+
+	otherAuthor := &VersionedAuthor{Name: "B2", DocID: d.ID}
+	err := mgmrel.HasMany(&staleDoc, &VersionedAuthor{}).Sync([]*VersionedAuthor{otherAuthor})
+	require.True(t, errors.Is(err, mgmrel.ErrConcurrentModification))
+
+	c, err := mgm.Coll(&VersionedAuthor{}).CountDocuments(mgm.Ctx(), bson.M{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), c)
+}
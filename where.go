@@ -0,0 +1,132 @@
+package mgmrel
+
+import (
+	"fmt"
+
+	"github.com/kamva/mgm/v3"
+	"github.com/kamva/mgm/v3/builder"
+	f "github.com/kamva/mgm/v3/field"
+	o "github.com/kamva/mgm/v3/operator"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// countOperators maps the Has comparison operators
+// to their mongo operator and its negation.
+var countOperators = map[string][2]string{
+	"=":  {o.Eq, o.Ne},
+	"!=": {o.Ne, o.Eq},
+	">":  {o.Gt, o.Lte},
+	">=": {o.Gte, o.Lt},
+	"<":  {o.Lt, o.Gte},
+	"<=": {o.Lte, o.Gt},
+}
+
+// WhereHas returns filter of the owners that have at least one related
+// model matching the child filter. the filter is usable on the owner's
+// collection. e.g mgm.Coll(&Doc{}).SimpleFind(&docs, filter)
+// childFilter can be nil.
+func WhereHas(r Relation, childFilter bson.M) (bson.M, error) {
+	ids, err := distinctOwners(r, childFilter)
+	if err != nil {
+		return nil, err
+	}
+	return bson.M{f.ID: bson.M{o.In: ids}}, nil
+}
+
+// WhereDoesntHave returns filter of the owners that do not have
+// any related model matching the child filter.
+// childFilter can be nil.
+func WhereDoesntHave(r Relation, childFilter bson.M) (bson.M, error) {
+	ids, err := distinctOwners(r, childFilter)
+	if err != nil {
+		return nil, err
+	}
+	return bson.M{f.ID: bson.M{o.Nin: ids}}, nil
+}
+
+// Has returns filter of the owners that count of their related models
+// satisfies the comparison. e.g mgmrel.Has(rel, ">=", 3)
+// valid operators are: =, !=, >, >=, <, <=
+func Has(r Relation, operator string, count int64) (bson.M, error) {
+	ops, ok := countOperators[operator]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidOperator, operator)
+	}
+
+	// Owners without related models are not in the grouped counts, so when
+	// zero count satisfies the comparison, we exclude the owners that
+	// their count does not satisfy it.
+	if compareCount(operator, 0, count) {
+		ids, err := countedOwners(r, ops[1], count)
+		if err != nil {
+			return nil, err
+		}
+		return bson.M{f.ID: bson.M{o.Nin: ids}}, nil
+	}
+
+	ids, err := countedOwners(r, ops[0], count)
+	if err != nil {
+		return nil, err
+	}
+	return bson.M{f.ID: bson.M{o.In: ids}}, nil
+}
+
+// relatedFilter returns filter of the related models that have
+// owner and match the relation's scope and the provided filter.
+func relatedFilter(r *relation, filter bson.M) bson.M {
+	result := bson.M{r.foreignKey: bson.M{o.Exists: true, o.Ne: nil}}
+	for k, v := range r.activeScope() {
+		result[k] = v
+	}
+	for k, v := range filter {
+		result[k] = v
+	}
+	return result
+}
+
+// distinctOwners returns the owner ids of the related models that
+// match the filter.
+func distinctOwners(rel Relation, filter bson.M) ([]interface{}, error) {
+	r := rel.base()
+	ids, err := mgm.Coll(r.related).Distinct(mgm.Ctx(), r.foreignKey, relatedFilter(r, filter))
+	if ids == nil {
+		// Mongo needs an array for the $in and $nin operators, not null.
+		ids = make([]interface{}, 0)
+	}
+	return ids, err
+}
+
+// countedOwners returns the owner ids that count of their
+// related models matches the count operator.
+func countedOwners(rel Relation, operator string, count int64) ([]interface{}, error) {
+	r := rel.base()
+
+	results := make([]integrityResult, 0)
+	err := mgm.Coll(r.related).SimpleAggregate(&results,
+		bson.M{o.Match: relatedFilter(r, nil)},
+		builder.Group("$"+r.foreignKey, bson.M{"count": bson.M{o.Sum: 1}}),
+		bson.M{o.Match: bson.M{"count": bson.M{operator: count}}},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return integrityResultIDs(results), nil
+}
+
+// compareCount compares the counts by the Has comparison operator.
+func compareCount(operator string, a, b int64) bool {
+	switch operator {
+	case "=":
+		return a == b
+	case "!=":
+		return a != b
+	case ">":
+		return a > b
+	case ">=":
+		return a >= b
+	case "<":
+		return a < b
+	default:
+		return a <= b
+	}
+}
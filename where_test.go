@@ -0,0 +1,75 @@
+package mgmrel_test
+
+import (
+	"errors"
+	mgmrel "github.com/kamva/mgm-relation"
+	"github.com/kamva/mgm/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"testing"
+)
+
+func findDocsBy(t *testing.T, filter bson.M) []Doc {
+	docs := make([]Doc, 0)
+	require.NoError(t, mgm.Coll(&Doc{}).SimpleFind(&docs, filter))
+	return docs
+}
+
+func TestWhereHas(t *testing.T) {
+	setupDefConnection()
+	resetCollection()
+	d, authors := insertHasManyRelation(t)
+	require.NoError(t, mgm.Coll(&Doc{}).Create(NewDoc("B", 13)))
+
+	filter, err := mgmrel.WhereHas(mgmrel.HasMany(&Doc{}, &DocAuthor{}), bson.M{"name": authors[0].Name})
+	require.NoError(t, err)
+	docs := findDocsBy(t, filter)
+	require.Equal(t, 1, len(docs))
+	assert.Equal(t, d.ID, docs[0].ID)
+
+	filter, err = mgmrel.WhereHas(mgmrel.HasMany(&Doc{}, &DocAuthor{}), bson.M{"name": "unknown"})
+	require.NoError(t, err)
+	assert.Equal(t, 0, len(findDocsBy(t, filter)))
+}
+
+func TestWhereDoesntHave(t *testing.T) {
+	setupDefConnection()
+	resetCollection()
+	insertHasManyRelation(t)
+	other := NewDoc("B", 13)
+	require.NoError(t, mgm.Coll(other).Create(other))
+
+	filter, err := mgmrel.WhereDoesntHave(mgmrel.HasOne(&Doc{}, &DocAuthor{}), nil)
+	require.NoError(t, err)
+	docs := findDocsBy(t, filter)
+	require.Equal(t, 1, len(docs))
+	assert.Equal(t, other.ID, docs[0].ID)
+}
+
+func TestHas(t *testing.T) {
+	setupDefConnection()
+	resetCollection()
+	d, _ := insertHasManyRelation(t)
+	other := NewDoc("B", 13)
+	require.NoError(t, mgm.Coll(other).Create(other))
+	rel := mgmrel.HasMany(&Doc{}, &DocAuthor{})
+
+	filter, err := mgmrel.Has(rel, ">=", 2)
+	require.NoError(t, err)
+	docs := findDocsBy(t, filter)
+	require.Equal(t, 1, len(docs))
+	assert.Equal(t, d.ID, docs[0].ID)
+
+	// Owners without related models satisfy the comparison too.
+	filter, err = mgmrel.Has(rel, "<", 2)
+	require.NoError(t, err)
+	docs = findDocsBy(t, filter)
+	require.Equal(t, 1, len(docs))
+	assert.Equal(t, other.ID, docs[0].ID)
+}
+
+func TestHas_InvalidOperator(t *testing.T) {
+	_, err := mgmrel.Has(mgmrel.HasMany(&Doc{}, &DocAuthor{}), "~", 1)
+	assert.True(t, errors.Is(err, mgmrel.ErrInvalidOperator))
+}